@@ -96,9 +96,12 @@ func (this *APIRequest) DoAsync(child common.IResult) {
 }
 
 //Do is the launch point for the request. It will 'do' the request according to the data that has been set, see each
-//data field for more information
+//data field for more information. The result has the api_name and url fields set, and status_code once a response is
+//received (see IResult.With). The result is made with the request's Context, so it carries the Context's trace (see
+//common.MakeCommonResultWithContext)
 func (this *APIRequest) Do() (result common.IResult) {
-	result = common.MakeCommonResult(this.config)
+	result = common.MakeCommonResultWithContext(this.Context, this.config)
+	result.With("api_name", this.ApiName).With("url", this.Url)
 	error_count := 0
 	result.Debugf("APIRequest.Do called for Method: %s, URL: %s", this.Method, this.Url)
 
@@ -111,13 +114,13 @@ func (this *APIRequest) Do() (result common.IResult) {
 		if this.RequestBody != nil {
 			requestBytes, json_err := json.Marshal(this.RequestBody)
 			if json_err != nil {
-				result.Errorf("Error marshalling requestBody. Err: %v", json_err)
+				result.Errorf("Error marshalling requestBody. Err: %w", json_err)
 				return
 			}
 			result.Debugf("Request Body to send: %s", string(requestBytes))
 			req, req_err = http.NewRequest(this.Method, this.Url, bytes.NewBuffer(requestBytes))
 			if req_err != nil {
-				result.Errorf("Error creating new request. Method: %s Url: %s Err: %v", this.Method, this.Url, req_err)
+				result.Errorf("Error creating new request. Method: %s Url: %s Err: %w", this.Method, this.Url, req_err)
 				error_count++
 				continue
 			}
@@ -125,7 +128,7 @@ func (this *APIRequest) Do() (result common.IResult) {
 		} else {
 			req, req_err = http.NewRequest(this.Method, this.Url, nil)
 			if req_err != nil {
-				result.Errorf("Error creating new request. Method: %s Url: %s Err: %v", this.Method, this.Url, req_err)
+				result.Errorf("Error creating new request. Method: %s Url: %s Err: %w", this.Method, this.Url, req_err)
 				error_count++
 				continue
 			}
@@ -148,12 +151,13 @@ func (this *APIRequest) Do() (result common.IResult) {
 		resp, do_err := this.client.Do(req)
 		result.DebugMessagef("Finished req. %s", time.Now().Format(time.RFC3339))
 		if do_err != nil {
-			result.Errorf("Error doing request. req: %s Err: %v", req, do_err)
+			result.Errorf("Error doing request. req: %s Err: %w", req, do_err)
 			error_count++
 			continue
 		}
 
 		this.HttpResponse = resp
+		result.With("status_code", resp.StatusCode)
 
 		//Verify we got the expected response code.
 		exp_response, ok := this.ValidResponses[resp.StatusCode]
@@ -169,7 +173,7 @@ func (this *APIRequest) Do() (result common.IResult) {
 			} else {
 				result.SetResponseMessage("DEPENDENCY_API_ERROR: " + err_resp.Error)
 			}
-			result.Infof("Bad response code returned for url: %s, valid http responses: %v, "+
+			result.Warnf("Bad response code returned for url: %s, valid http responses: %v, "+
 				"http response code returned: %d Response Body: %s",
 				this.Url,
 				this.ValidResponses,
@@ -192,7 +196,7 @@ func (this *APIRequest) Do() (result common.IResult) {
 			case *[]byte:
 				tmp, err := ioutil.ReadAll(resp.Body)
 				if err != nil {
-					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %s", this.Url, string(tmp), err)
+					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %w", this.Url, string(tmp), err)
 					error_count++
 					resp.Body.Close()
 					continue
@@ -203,7 +207,7 @@ func (this *APIRequest) Do() (result common.IResult) {
 				body, _ := ioutil.ReadAll(resp.Body)
 				json_err := json.Unmarshal(body, exp_response)
 				if json_err != nil {
-					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %s", this.Url, string(body), json_err)
+					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %w", this.Url, string(body), json_err)
 					error_count++
 					resp.Body.Close()
 					continue
@@ -222,7 +226,7 @@ func (this *APIRequest) Do() (result common.IResult) {
 			//If we're supposed to validate the payload, check it
 			if v, ok := exp_response.(IPayload); ok {
 				if err := v.Valid(); err != nil {
-					result.Errorf("ExpectedResponseBody.(IPayload) returned invalid payload with error: %s", err.Error())
+					result.Errorf("ExpectedResponseBody.(IPayload) returned invalid payload with error: %w", err)
 					error_count++
 					continue
 				}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"github.com/BrandonEchols/common-go-utils/common"
+	"strings"
+	"testing"
+)
+
+func getTestKey(test *testing.T) []byte {
+	encoded, err := common.GenerateConfigMasterKey()
+	if err != nil {
+		test.Fatal(err)
+	}
+	key, err := common.ParseConfigMasterKey(encoded)
+	if err != nil {
+		test.Fatal(err)
+	}
+	return key
+}
+
+//parseTestConfig parses contents the way the config getter would parse a file of the given format
+func parseTestConfig(test *testing.T, format string, contents string) map[string]string {
+	values, err := common.ParseConfigFile("config"+format, []byte(contents))
+	if err != nil {
+		test.Fatalf("Could not parse %s contents '%s'. Err: %v", format, contents, err)
+	}
+	return values
+}
+
+func TestRunReturnsAUsageErrorWithoutACommand(test *testing.T) {
+	if err := run([]string{}, "", ""); err != USAGE_ERR {
+		test.Errorf("Expected USAGE_ERR, got %v", err)
+	}
+}
+
+func TestEncryptAndDecryptKeys(test *testing.T) {
+	key := getTestKey(test)
+
+	testCases := map[string]struct {
+		format    string
+		contents  string
+		keys      []string
+		expectErr string
+		encrypted []string          //The config vars that should be encrypted in the file
+		expected  map[string]string //The config vars' values once the file is decrypted again
+	}{
+		"json": {
+			format:    ".json",
+			contents:  "{\n  \"DB_PASSWORD\": \"hunter2\",\n  \"LOGGING_LEVEL\": \"INFO\"\n}\n",
+			keys:      []string{"DB_PASSWORD"},
+			encrypted: []string{"DB_PASSWORD"},
+			expected:  map[string]string{"DB_PASSWORD": "hunter2", "LOGGING_LEVEL": "INFO"},
+		},
+		"json nested key": {
+			format:    ".json",
+			contents:  `{"db": {"password": "hunter \"2\""}, "port": "5432"}`,
+			keys:      []string{"password"},
+			encrypted: []string{"db.password"},
+			expected:  map[string]string{"db.password": `hunter "2"`, "port": "5432"},
+		},
+		"json dotted nested key": {
+			format:    ".json",
+			contents:  `{"db": {"password": "hunter2"}}`,
+			keys:      []string{"db.password"},
+			expectErr: "db.password is not a string value in the file",
+		},
+		"json duplicate key": {
+			format:    ".json",
+			contents:  `{"primary": {"password": "a"}, "replica": {"password": "b"}}`,
+			keys:      []string{"password"},
+			expectErr: "password is defined more than once",
+		},
+		"json already encrypted": {
+			format:    ".json",
+			contents:  `{"DB_PASSWORD": "hunter2"}`,
+			keys:      []string{"DB_PASSWORD", "DB_PASSWORD"},
+			encrypted: []string{"DB_PASSWORD"},
+			expected:  map[string]string{"DB_PASSWORD": "hunter2"},
+		},
+		"env": {
+			format:    ".env",
+			contents:  "# Database\nDB_PASSWORD=hunter2 # the password\nexport API_KEY='k e y'\nLOGGING_LEVEL=INFO\n",
+			keys:      []string{"DB_PASSWORD", "API_KEY"},
+			encrypted: []string{"DB_PASSWORD", "API_KEY"},
+			expected:  map[string]string{"DB_PASSWORD": "hunter2", "API_KEY": "k e y", "LOGGING_LEVEL": "INFO"},
+		},
+		"env duplicate key": {
+			format:    ".env",
+			contents:  "DB_PASSWORD=a\nDB_PASSWORD=b\n",
+			keys:      []string{"DB_PASSWORD"},
+			expectErr: "DB_PASSWORD is defined more than once",
+		},
+		"unsupported format": {
+			format:    ".yaml",
+			contents:  "DB_PASSWORD: hunter2\n",
+			keys:      []string{"DB_PASSWORD"},
+			expectErr: "only .json and .env files can be edited by key",
+		},
+	}
+
+	for name, testCase := range testCases {
+		encrypted, err := encryptKeys(testCase.contents, testCase.format, testCase.keys, key)
+		if testCase.expectErr != "" {
+			if err == nil || err.Error() != testCase.expectErr {
+				test.Errorf("%s: Expected error '%s', got %v", name, testCase.expectErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			test.Errorf("%s: Unexpected error encrypting: %v", name, err)
+			continue
+		}
+
+		encrypted_values := parseTestConfig(test, testCase.format, encrypted)
+		for _, config_key := range testCase.encrypted {
+			if !strings.HasPrefix(encrypted_values[config_key], common.CONFIG_ENCRYPTED_PREFIX) {
+				test.Errorf("%s: Expected %s to be encrypted, got '%s'", name, config_key, encrypted_values[config_key])
+			}
+		}
+		if strings.Count(encrypted, common.CONFIG_ENCRYPTED_PREFIX) != len(testCase.encrypted) {
+			test.Errorf("%s: Expected %d encrypted values, got '%s'", name, len(testCase.encrypted), encrypted)
+		}
+
+		//Decrypting by key and decrypting every value should both give back the original values
+		for _, keys := range [][]string{testCase.keys[:1], nil} {
+			decrypted, err := decryptKeys(encrypted, testCase.format, keys, key)
+			if err != nil {
+				test.Errorf("%s: Unexpected error decrypting %v: %v", name, keys, err)
+				continue
+			}
+			if keys == nil {
+				for config_key, value := range testCase.expected {
+					if actual := parseTestConfig(test, testCase.format, decrypted)[config_key]; actual != value {
+						test.Errorf("%s: Expected %s to be decrypted to '%s', got '%s'", name, config_key, value, actual)
+					}
+				}
+			} else if strings.Count(decrypted, common.CONFIG_ENCRYPTED_PREFIX) != len(testCase.encrypted)-1 {
+				test.Errorf("%s: Expected only %v to be decrypted, got '%s'", name, keys, decrypted)
+			}
+		}
+	}
+}
+
+func TestDecryptKeysErrors(test *testing.T) {
+	key := getTestKey(test)
+	encrypted, err := encryptKeys(`{"DB_PASSWORD": "hunter2", "LOGGING_LEVEL": "INFO"}`, ".json", []string{"DB_PASSWORD"}, key)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		keys      []string
+		key       []byte
+		expectErr string
+	}{
+		"not encrypted": {[]string{"LOGGING_LEVEL"}, key, "LOGGING_LEVEL is not encrypted"},
+		"missing key":   {[]string{"MISSING"}, key, "MISSING is not a string value in the file"},
+		"wrong key":     {[]string{"DB_PASSWORD"}, getTestKey(test), "DB_PASSWORD: "},
+		"wrong key all": {nil, getTestKey(test), ""},
+	}
+
+	for name, testCase := range testCases {
+		_, err := decryptKeys(encrypted, ".json", testCase.keys, testCase.key)
+		if err == nil || !strings.HasPrefix(err.Error(), testCase.expectErr) {
+			test.Errorf("%s: Expected an error starting with '%s', got %v", name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestRotateValues(test *testing.T) {
+	old_key := getTestKey(test)
+	new_key := getTestKey(test)
+
+	testCases := map[string]struct {
+		format   string
+		contents string
+		keys     []string
+	}{
+		"json": {
+			format:   ".json",
+			contents: `{"DB_PASSWORD": "hunter2", "db": {"password": "nested"}, "LOGGING_LEVEL": "INFO"}`,
+			keys:     []string{"DB_PASSWORD", "password"},
+		},
+		"env": {
+			format:   ".env",
+			contents: "DB_PASSWORD=hunter2 # comment\nexport API_KEY='k e y'\nLOGGING_LEVEL=INFO\n",
+			keys:     []string{"DB_PASSWORD", "API_KEY"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		encrypted, err := encryptKeys(testCase.contents, testCase.format, testCase.keys, old_key)
+		if err != nil {
+			test.Errorf("%s: Unexpected error encrypting: %v", name, err)
+			continue
+		}
+		rotated, err := rotateValues(encrypted, old_key, new_key)
+		if err != nil {
+			test.Errorf("%s: Unexpected error rotating: %v", name, err)
+			continue
+		}
+		if rotated == encrypted || strings.Count(rotated, common.CONFIG_ENCRYPTED_PREFIX) != len(testCase.keys) {
+			test.Errorf("%s: Expected every value to be re-encrypted, got '%s'", name, rotated)
+		}
+		if _, err := rotateValues(rotated, old_key, new_key); err == nil {
+			test.Errorf("%s: Expected rotating with the old key again to fail", name)
+		}
+
+		decrypted, err := decryptKeys(rotated, testCase.format, nil, new_key)
+		if err != nil {
+			test.Errorf("%s: Unexpected error decrypting with the new key: %v", name, err)
+			continue
+		}
+		expected := parseTestConfig(test, testCase.format, testCase.contents)
+		actual := parseTestConfig(test, testCase.format, decrypted)
+		for config_key, value := range expected {
+			if actual[config_key] != value {
+				test.Errorf("%s: Expected %s to be '%s' after rotating, got '%s'", name, config_key, value, actual[config_key])
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+/*
+	configcrypt encrypts, decrypts and rotates the encrypted (enc:v1:...) values in a config file, see
+	common/ConfigCrypt.go. The master key is read from CONFIG_MASTER_KEY or CONFIG_MASTER_KEY_FILE, or from -key-file.
+
+	Usage:
+		configcrypt generate-key
+		configcrypt encrypt-value [VALUE]              Prints VALUE (or stdin) encrypted
+		configcrypt encrypt FILE KEY...                Encrypts the values of KEY... in FILE
+		configcrypt decrypt FILE [KEY...]              Decrypts the values of KEY... (or all encrypted values) in FILE
+		configcrypt -new-key-file PATH rotate FILE     Re-encrypts all encrypted values in FILE with the key in PATH
+
+	Files are edited in place, keeping their layout and comments. encrypt and decrypt support .json and .env files
+	(optionally ending in .dist), and only match keys as they're written in the file, e.g. "pool" rather than
+	"db.pool" for a nested JSON object. rotate works on any config file.
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//Returned by run when it isn't given a command
+var USAGE_ERR = errors.New("no command given")
+
+//Matches an encrypted value, along with any quotes around it
+var encrypted_value_pattern = regexp.MustCompile(`"?` + regexp.QuoteMeta(common.CONFIG_ENCRYPTED_PREFIX) + `[A-Za-z0-9+/=]+"?`)
+
+func main() {
+	key_file := flag.String("key-file", "", "File containing the base64 master key, instead of "+common.CONFIG_MASTER_KEY_ENV_VAR)
+	new_key_file := flag.String("new-key-file", "", "File containing the base64 master key to rotate to")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: configcrypt [flags] generate-key | encrypt-value [VALUE] | encrypt FILE KEY... | decrypt FILE [KEY...] | rotate FILE")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	err := run(flag.Args(), *key_file, *new_key_file)
+	if err == USAGE_ERR {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "configcrypt: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(args []string, key_file string, new_key_file string) error {
+	if len(args) == 0 {
+		return USAGE_ERR
+	}
+	if args[0] == "generate-key" {
+		key, err := common.GenerateConfigMasterKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	}
+
+	key, err := loadKey(key_file)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "encrypt-value":
+		plaintext := ""
+		if len(args) > 1 {
+			plaintext = args[1]
+		} else {
+			contents, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			plaintext = strings.TrimRight(string(contents), "\r\n")
+		}
+		encrypted, err := common.EncryptConfigValue(key, plaintext)
+		if err != nil {
+			return err
+		}
+		fmt.Println(encrypted)
+		return nil
+	case "encrypt":
+		if len(args) < 3 {
+			return errors.New("usage: configcrypt encrypt FILE KEY...")
+		}
+		return editFile(args[1], func(contents string) (string, error) {
+			return encryptKeys(contents, common.ConfigFileFormat(args[1]), args[2:], key)
+		})
+	case "decrypt":
+		if len(args) < 2 {
+			return errors.New("usage: configcrypt decrypt FILE [KEY...]")
+		}
+		return editFile(args[1], func(contents string) (string, error) {
+			return decryptKeys(contents, common.ConfigFileFormat(args[1]), args[2:], key)
+		})
+	case "rotate":
+		if len(args) != 2 || new_key_file == "" {
+			return errors.New("usage: configcrypt -new-key-file PATH rotate FILE")
+		}
+		new_key, err := loadKey(new_key_file)
+		if err != nil {
+			return err
+		}
+		return editFile(args[1], func(contents string) (string, error) {
+			return rotateValues(contents, key, new_key)
+		})
+	default:
+		return errors.New("unknown command " + args[0])
+	}
+}
+
+func loadKey(key_file string) ([]byte, error) {
+	if key_file == "" {
+		return common.LoadConfigMasterKey()
+	}
+	contents, err := ioutil.ReadFile(key_file)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseConfigMasterKey(string(contents))
+}
+
+//editFile replaces the contents of path with the result of edit, keeping the file's permissions
+func editFile(path string, edit func(contents string) (string, error)) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	edited, err := edit(string(contents))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return ioutil.WriteFile(path, []byte(edited), info.Mode())
+}
+
+//valuePattern matches the value of key in a file of the given format. The value is the pattern's last group.
+func valuePattern(format string, key string) (*regexp.Regexp, error) {
+	switch format {
+	case ".json":
+		return regexp.MustCompile(`("` + regexp.QuoteMeta(key) + `"\s*:\s*)("(?:[^"\\]|\\.)*")`), nil
+	case ".env":
+		return regexp.MustCompile(`(?m)^(\s*(?:export\s+)?` + regexp.QuoteMeta(key) + `\s*=[ \t]*)("(?:[^"\\]|\\.)*"|'[^']*'|[^\r\n]*?)(?:[ \t]+#.*?)?[ \t]*\r?$`), nil
+	default:
+		return nil, errors.New("only .json and .env files can be edited by key")
+	}
+}
+
+//findValue returns the location of the value of key in contents, as returned by FindStringSubmatchIndex
+func findValue(contents string, format string, key string) ([]int, error) {
+	pattern, err := valuePattern(format, key)
+	if err != nil {
+		return nil, err
+	}
+	matches := pattern.FindAllStringSubmatchIndex(contents, -1)
+	if len(matches) == 0 {
+		return nil, errors.New(key + " is not a string value in the file")
+	}
+	if len(matches) > 1 {
+		return nil, errors.New(key + " is defined more than once")
+	}
+	return matches[0], nil
+}
+
+func encryptKeys(contents string, format string, keys []string, key []byte) (string, error) {
+	for _, config_key := range keys {
+		match, err := findValue(contents, format, config_key)
+		if err != nil {
+			return "", err
+		}
+		written := contents[match[4]:match[5]]
+		value, err := unquoteValue(format, written)
+		if err != nil {
+			return "", errors.New(config_key + " has an invalid value: " + err.Error())
+		}
+		if strings.HasPrefix(value, common.CONFIG_ENCRYPTED_PREFIX) {
+			continue
+		}
+
+		encrypted, err := common.EncryptConfigValue(key, value)
+		if err != nil {
+			return "", err
+		}
+		contents = contents[:match[4]] + quoteValue(format, encrypted) + contents[match[5]:]
+	}
+	return contents, nil
+}
+
+func decryptKeys(contents string, format string, keys []string, key []byte) (string, error) {
+	if len(keys) == 0 {
+		//Decrypt every encrypted value in the file
+		var decrypt_err error
+		contents = encrypted_value_pattern.ReplaceAllStringFunc(contents, func(written string) string {
+			value := strings.Trim(written, `"`)
+			decrypted, err := common.DecryptConfigValue(key, value)
+			if err != nil {
+				decrypt_err = err
+				return written
+			}
+			return quoteValue(format, decrypted)
+		})
+		return contents, decrypt_err
+	}
+
+	for _, config_key := range keys {
+		match, err := findValue(contents, format, config_key)
+		if err != nil {
+			return "", err
+		}
+		value, err := unquoteValue(format, contents[match[4]:match[5]])
+		if err != nil {
+			return "", errors.New(config_key + " has an invalid value: " + err.Error())
+		}
+		if !strings.HasPrefix(value, common.CONFIG_ENCRYPTED_PREFIX) {
+			return "", errors.New(config_key + " is not encrypted")
+		}
+		decrypted, err := common.DecryptConfigValue(key, value)
+		if err != nil {
+			return "", errors.New(config_key + ": " + err.Error())
+		}
+		contents = contents[:match[4]] + quoteValue(format, decrypted) + contents[match[5]:]
+	}
+	return contents, nil
+}
+
+func rotateValues(contents string, old_key []byte, new_key []byte) (string, error) {
+	var rotate_err error
+	rotated := encrypted_value_pattern.ReplaceAllStringFunc(contents, func(written string) string {
+		value := strings.Trim(written, `"`)
+		decrypted, err := common.DecryptConfigValue(old_key, value)
+		if err != nil {
+			rotate_err = err
+			return written
+		}
+		encrypted, err := common.EncryptConfigValue(new_key, decrypted)
+		if err != nil {
+			rotate_err = err
+			return written
+		}
+		return strings.Replace(written, value, encrypted, 1)
+	})
+	return rotated, rotate_err
+}
+
+//unquoteValue returns the value written in a file of the given format
+func unquoteValue(format string, written string) (string, error) {
+	if format == ".json" {
+		value := ""
+		err := json.Unmarshal([]byte(written), &value)
+		return value, err
+	}
+
+	switch {
+	case len(written) >= 2 && written[0] == '"' && written[len(written)-1] == '"':
+		return strconv.Unquote(written)
+	case len(written) >= 2 && written[0] == '\'' && written[len(written)-1] == '\'':
+		return written[1 : len(written)-1], nil
+	default:
+		if comment := strings.Index(written, " #"); comment != -1 {
+			written = strings.TrimSpace(written[:comment])
+		}
+		return written, nil
+	}
+}
+
+//quoteValue returns value as it should be written in a file of the given format
+func quoteValue(format string, value string) string {
+	if format == ".json" {
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	}
+	if value == "" || strings.ContainsAny(value, " \t\r\n#\"'\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Matches the key of a MustGetConfigVar("KEY") call
+var must_get_config_var_pattern = regexp.MustCompile(`MustGetConfigVar\(\s*"([^"]+)"\s*\)`)
+
+/*
+	lintConfigFile checks a single config file
+	@returns
+		map[string]string The parsed config values, or nil if the file couldn't be parsed
+		[]lintIssue The problems found in the file
+*/
+func lintConfigFile(config_file string) (map[string]string, []lintIssue) {
+	contents, err := ioutil.ReadFile(config_file)
+	if err != nil {
+		return nil, []lintIssue{{path: config_file, message: err.Error()}}
+	}
+
+	file_data, err := common.ParseConfigFile(config_file, contents)
+	if err != nil {
+		return nil, []lintIssue{{path: config_file, message: "invalid config file: " + err.Error()}}
+	}
+
+	issues := []lintIssue{}
+	if isJsonConfigFile(config_file) {
+		issues = append(issues, scanJsonConfig(config_file, common.StripConfigComments(contents))...)
+	}
+	for _, key := range sortedKeys(file_data) {
+		if file_data[key] == "" {
+			issues = append(issues, lintIssue{
+				path:    config_file,
+				warning: true,
+				message: key + " is an empty string, which SafeGetConfigVar can't tell apart from an unset key",
+			})
+		}
+	}
+	return file_data, issues
+}
+
+//isJsonConfigFile mirrors the config getter's choice of format, which treats unknown extensions as JSON
+func isJsonConfigFile(config_file string) bool {
+	switch common.ConfigFileFormat(config_file) {
+	case ".yaml", ".yml", ".toml", ".env":
+		return false
+	default:
+		return true
+	}
+}
+
+/*
+	scanJsonConfig walks the tokens of a JSON config file (which has already parsed successfully) looking for
+	duplicate keys and non-string values, which json.Unmarshal quietly accepts
+*/
+func scanJsonConfig(config_file string, json_contents string) []lintIssue {
+	scanner := jsonScanner{
+		config_file: config_file,
+		contents:    json_contents,
+		decoder:     json.NewDecoder(strings.NewReader(json_contents)),
+	}
+	scanner.decoder.UseNumber()
+	if _, err := scanner.decoder.Token(); err != nil {
+		return []lintIssue{{path: config_file, message: "invalid JSON: " + err.Error()}}
+	}
+	if err := scanner.scanObject(""); err != nil {
+		return append(scanner.issues, lintIssue{path: config_file, message: "invalid JSON: " + err.Error()})
+	}
+	return scanner.issues
+}
+
+type jsonScanner struct {
+	config_file string
+	contents    string
+	decoder     *json.Decoder
+	issues      []lintIssue
+}
+
+//scanObject scans the members of an object whose opening '{' has been read, up to and including its closing '}'
+func (this *jsonScanner) scanObject(prefix string) error {
+	seen := map[string]bool{}
+	for this.decoder.More() {
+		token, err := this.decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + token.(string)
+		if seen[key] {
+			this.addIssue(false, key+" is defined more than once")
+		}
+		seen[key] = true
+
+		token, err = this.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch value := token.(type) {
+		case json.Delim:
+			if value == '{' {
+				err = this.scanObject(key + ".")
+			} else {
+				err = this.skipArray()
+			}
+			if err != nil {
+				return err
+			}
+		case json.Number:
+			this.addIssue(true, key+" is a number rather than a string")
+		case bool:
+			this.addIssue(true, key+" is a boolean rather than a string")
+		case nil:
+			this.addIssue(true, key+" is null rather than a string")
+		}
+	}
+	_, err := this.decoder.Token()
+	return err
+}
+
+//skipArray skips the rest of an array whose opening '[' has been read
+func (this *jsonScanner) skipArray() error {
+	for depth := 1; depth > 0; {
+		token, err := this.decoder.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			if delim == '[' || delim == '{' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+//addIssue records an issue at the line of the token that was just read
+func (this *jsonScanner) addIssue(warning bool, message string) {
+	offset := int(this.decoder.InputOffset())
+	line := strings.Count(this.contents[:offset], "\n") + 1
+	this.issues = append(this.issues, lintIssue{path: this.config_file, line: line, warning: warning, message: message})
+}
+
+/*
+	overridePairs finds the config files (and their .dist defaults) among config_files that both exist
+	@returns
+		[][2]string Pairs of [defaults file, override file]
+*/
+func overridePairs(config_files []string) [][2]string {
+	pairs := [][2]string{}
+	seen := map[string]bool{}
+	for _, config_file := range config_files {
+		override_file := strings.TrimSuffix(config_file, ".dist")
+		if seen[override_file] {
+			continue
+		}
+		seen[override_file] = true
+		if !fileExists(override_file) || !fileExists(override_file+".dist") {
+			continue
+		}
+		pairs = append(pairs, [2]string{override_file + ".dist", override_file})
+	}
+	return pairs
+}
+
+//diffConfigFiles compares the keys of a config file with its .dist defaults
+func diffConfigFiles(dist_file string, override_file string, parsed map[string]map[string]string) []lintIssue {
+	dist_data, dist_ok := loadParsed(dist_file, parsed)
+	override_data, override_ok := loadParsed(override_file, parsed)
+	if !dist_ok || !override_ok {
+		return nil //Already reported as invalid
+	}
+
+	issues := []lintIssue{}
+	for _, key := range sortedKeys(override_data) {
+		if _, ok := dist_data[key]; !ok {
+			issues = append(issues, lintIssue{
+				path:    override_file,
+				message: fmt.Sprintf("%s is not defined in %s", key, dist_file),
+			})
+		}
+	}
+	for _, key := range sortedKeys(dist_data) {
+		if _, ok := override_data[key]; !ok {
+			issues = append(issues, lintIssue{
+				path:    override_file,
+				warning: true,
+				message: fmt.Sprintf("%s is missing, the default from %s is used", key, dist_file),
+			})
+		}
+	}
+	return issues
+}
+
+//loadParsed returns the config values of config_file, parsing it if it wasn't one of the files being linted
+func loadParsed(config_file string, parsed map[string]map[string]string) (map[string]string, bool) {
+	if file_data, ok := parsed[config_file]; ok {
+		return file_data, true
+	}
+	contents, err := ioutil.ReadFile(config_file)
+	if err != nil {
+		return nil, false
+	}
+	file_data, err := common.ParseConfigFile(config_file, contents)
+	return file_data, err == nil
+}
+
+//lintSources finds MustGetConfigVar("KEY") calls under src_dir whose KEY isn't in defined_keys
+func lintSources(src_dir string, defined_keys map[string]bool) []lintIssue {
+	issues := []lintIssue{}
+	err := filepath.Walk(src_dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != src_dir && (info.Name() == "vendor" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range must_get_config_var_pattern.FindAllStringSubmatchIndex(string(contents), -1) {
+			key := string(contents[match[2]:match[3]])
+			if !defined_keys[key] {
+				issues = append(issues, lintIssue{
+					path:    path,
+					line:    strings.Count(string(contents[:match[0]]), "\n") + 1,
+					message: "MustGetConfigVar(\"" + key + "\") is not defined in any config file",
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		issues = append(issues, lintIssue{path: src_dir, message: err.Error()})
+	}
+	return issues
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func sortedKeys(file_data map[string]string) []string {
+	keys := make([]string, 0, len(file_data))
+	for key := range file_data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+)
+
+func assertIssues(test *testing.T, name string, issues []lintIssue, expected []string) {
+	if len(issues) != len(expected) {
+		test.Errorf("%s: Expected %d issues, got %d: %v", name, len(expected), len(issues), issues)
+		return
+	}
+	for i, issue := range issues {
+		if issue.String() != expected[i] {
+			test.Errorf("%s: Expected issue '%s', got '%s'", name, expected[i], issue.String())
+		}
+	}
+}
+
+func TestLintConfigFileFindsDuplicateKeysAndNonStringValues(test *testing.T) {
+	file_data, issues := lintConfigFile("testdata/duplicates.json")
+	if file_data == nil || file_data["db.host"] != "127.0.0.1" || file_data["LOGGING_LEVEL"] != "DEBUG" {
+		test.Errorf("Expected the file to parse the way the config getter parses it, got %v", file_data)
+	}
+	assertIssues(test, "duplicates.json", issues, []string{
+		"testdata/duplicates.json:6: error: db.host is defined more than once",
+		"testdata/duplicates.json:9: warning: RETRIES is a number rather than a string",
+		"testdata/duplicates.json:10: warning: VERBOSE is a boolean rather than a string",
+		"testdata/duplicates.json:12: error: LOGGING_LEVEL is defined more than once",
+		"testdata/duplicates.json: warning: API_KEY is an empty string, which SafeGetConfigVar can't tell apart from an unset key",
+	})
+
+	testCases := map[string]struct {
+		contents string
+		expected []string
+	}{
+		"nested duplicate": {
+			contents: `{"a": {"b": {"c": "1", "c": "2"}}}`,
+			expected: []string{"config.json:1: error: a.b.c is defined more than once"},
+		},
+		"same key in different objects": {
+			contents: `{"primary": {"host": "a"}, "replica": {"host": "b"}}`,
+			expected: []string{},
+		},
+		"arrays are skipped": {
+			contents: "{\n\"hosts\": [\"a\", {\"b\": 1}, [2]],\n\"hosts\": \"c\",\n\"PORT\": null\n}",
+			expected: []string{
+				"config.json:3: error: hosts is defined more than once",
+				"config.json:4: warning: PORT is null rather than a string",
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		assertIssues(test, name, scanJsonConfig("config.json", testCase.contents), testCase.expected)
+	}
+}
+
+func TestLintDiffsConfigFilesWithTheirDefaults(test *testing.T) {
+	for _, config_files := range [][]string{
+		{"testdata/config.json"},
+		{"testdata/config.json.dist"},
+		{"testdata/config.json", "testdata/config.json.dist"},
+	} {
+		pairs := overridePairs(config_files)
+		if len(pairs) != 1 || pairs[0] != [2]string{"testdata/config.json.dist", "testdata/config.json"} {
+			test.Errorf("Expected one pair of config.json and its defaults for %v, got %v", config_files, pairs)
+		}
+	}
+	if pairs := overridePairs([]string{"testdata/duplicates.json"}); len(pairs) != 0 {
+		test.Errorf("Expected no pairs for a file without defaults, got %v", pairs)
+	}
+
+	issues := diffConfigFiles("testdata/config.json.dist", "testdata/config.json", map[string]map[string]string{})
+	assertIssues(test, "config.json", issues, []string{
+		"testdata/config.json: error: DEBUG_TOOLBAR is not defined in testdata/config.json.dist",
+		"testdata/config.json: warning: API_HOST is missing, the default from testdata/config.json.dist is used",
+	})
+}
+
+func TestLintSourcesFindsUndefinedMustGetConfigVarKeys(test *testing.T) {
+	defined_keys := map[string]bool{"PORT": true}
+	assertIssues(test, "src", lintSources("testdata/src", defined_keys), []string{
+		`testdata/src/app.go:7: error: MustGetConfigVar("ADMIN_PORT") is not defined in any config file`,
+	})
+
+	//lint checks the sources against the keys of every config file
+	assertIssues(test, "lint", lint([]string{"testdata/config.json"}, "testdata/src"), []string{
+		"testdata/config.json: error: DEBUG_TOOLBAR is not defined in testdata/config.json.dist",
+		"testdata/config.json: warning: API_HOST is missing, the default from testdata/config.json.dist is used",
+		`testdata/src/app.go:7: error: MustGetConfigVar("ADMIN_PORT") is not defined in any config file`,
+	})
+}
@@ -0,0 +1,105 @@
+/*
+	configlint checks config files for mistakes, for use in CI. It exits with status 1 if it finds any errors (or any
+	warnings, with -strict).
+
+	Usage:
+		configlint [-src DIR] [-strict] FILE...
+
+	Each FILE is checked for:
+		errors    The file doesn't parse the way the config getter parses it (for JSON, after '##' comments are removed)
+		errors    A JSON object defines the same key twice
+		warnings  A JSON value is a number, boolean or null rather than a string
+		warnings  A value is an empty string, which SafeGetConfigVar can't tell apart from an unset key
+	When both config.json and config.json.dist exist (for any FILE, with or without .dist), keys that config.json
+	overrides but config.json.dist doesn't define are errors, and keys that config.json doesn't override are listed as
+	warnings. With -src, the Go sources under DIR are checked for MustGetConfigVar("KEY") calls whose KEY isn't defined
+	in any FILE, which are errors.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//A problem found by configlint
+type lintIssue struct {
+	path    string
+	line    int //0 if the issue isn't about a specific line
+	warning bool
+	message string
+}
+
+func (this lintIssue) String() string {
+	location := this.path
+	if this.line > 0 {
+		location = fmt.Sprintf("%s:%d", this.path, this.line)
+	}
+	severity := "error"
+	if this.warning {
+		severity = "warning"
+	}
+	return location + ": " + severity + ": " + this.message
+}
+
+func main() {
+	src_dir := flag.String("src", "", "Check the Go sources under this directory for MustGetConfigVar keys that aren't defined")
+	strict := flag.Bool("strict", false, "Exit non-zero on warnings as well as errors")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: configlint [-src DIR] [-strict] FILE...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	issues := lint(flag.Args(), *src_dir)
+	failed := false
+	for _, issue := range issues {
+		fmt.Println(issue.String())
+		if !issue.warning || *strict {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+//lint runs every check on config_files, and on the Go sources under src_dir if it isn't empty
+func lint(config_files []string, src_dir string) []lintIssue {
+	issues := []lintIssue{}
+	defined_keys := map[string]bool{}
+	parsed := map[string]map[string]string{}
+	for _, config_file := range config_files {
+		file_data, file_issues := lintConfigFile(config_file)
+		issues = append(issues, file_issues...)
+		if file_data == nil {
+			continue
+		}
+		parsed[config_file] = file_data
+		for key := range file_data {
+			defined_keys[key] = true
+		}
+	}
+
+	for _, pair := range overridePairs(config_files) {
+		issues = append(issues, diffConfigFiles(pair[0], pair[1], parsed)...)
+	}
+
+	if src_dir != "" {
+		issues = append(issues, lintSources(src_dir, defined_keys)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].path != issues[j].path {
+			return issues[i].path < issues[j].path
+		}
+		return issues[i].line < issues[j].line
+	})
+	return issues
+}
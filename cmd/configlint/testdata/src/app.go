@@ -0,0 +1,8 @@
+package app
+
+import "github.com/BrandonEchols/common-go-utils/common"
+
+func ports(configs common.IConfigGetter) (string, string) {
+	return configs.MustGetConfigVar("PORT"),
+		configs.MustGetConfigVar( "ADMIN_PORT" )
+}
@@ -0,0 +1,6 @@
+package app
+
+import "github.com/BrandonEchols/common-go-utils/common"
+
+var test_configs = common.GetConfigGetter("config.json")
+var test_only = test_configs.MustGetConfigVar("TEST_ONLY_KEY")
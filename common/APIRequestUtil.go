@@ -80,19 +80,19 @@ func (this APIRequestUtils) DoRequest(
 	if requestBody != nil {
 		requestBytes, json_err := json.Marshal(requestBody)
 		if json_err != nil {
-			result.Errorf("Error marshalling requestBody in doRequest. Err: %v", json_err)
+			result.Errorf("Error marshalling requestBody in doRequest. Err: %w", json_err)
 			return
 		}
 		req, req_err = http.NewRequest(method, url, bytes.NewBuffer(requestBytes))
 		if req_err != nil {
-			result.Errorf("Error creating new request. Method: %s Url: %s Err: %v", method, url, req_err)
+			result.Errorf("Error creating new request. Method: %s Url: %s Err: %w", method, url, req_err)
 			return
 		}
 		req.Header.Set("Content-Type", "application/json")
 	} else {
 		req, req_err = http.NewRequest(method, url, nil)
 		if req_err != nil {
-			result.Errorf("Error creating new request. Method: %s Url: %s Err: %v", method, url, req_err)
+			result.Errorf("Error creating new request. Method: %s Url: %s Err: %w", method, url, req_err)
 			return
 		}
 	}
@@ -106,7 +106,7 @@ func (this APIRequestUtils) DoRequest(
 	resp, do_err := this.client.Do(req)
 	result.DebugMessagef("Finished req. %s", time.Now().Format(time.RFC3339))
 	if do_err != nil {
-		result.Errorf("Error doing request in doRequest. req: %s Err: %v", req, do_err)
+		result.Errorf("Error doing request in doRequest. req: %s Err: %w", req, do_err)
 		return
 	}
 
@@ -157,7 +157,7 @@ func (this APIRequestUtils) RequestWithRetries(
 				err_resp.Error = string(body_bytes)
 			}
 			result.SetResponseMessage(err_resp.Error)
-			result.Infof("Bad response code returned for url: %s, valid http responses: %v, "+
+			result.Warnf("Bad response code returned for url: %s, valid http responses: %v, "+
 				"http response code returned: %d Response Body: %s",
 				url,
 				valid_response_codes,
@@ -175,7 +175,7 @@ func (this APIRequestUtils) RequestWithRetries(
 			case *[]byte:
 				tmp, err := ioutil.ReadAll(resp.Body)
 				if err != nil {
-					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %s", url, string(tmp), err)
+					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %w", url, string(tmp), err)
 					error_count++
 					resp.Body.Close()
 					continue
@@ -186,7 +186,7 @@ func (this APIRequestUtils) RequestWithRetries(
 				body, _ := ioutil.ReadAll(resp.Body)
 				json_err := json.Unmarshal(body, expected_response_struct)
 				if json_err != nil {
-					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %s", url, string(body), json_err)
+					result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %w", url, string(body), json_err)
 					error_count++
 					resp.Body.Close()
 					continue
@@ -272,7 +272,7 @@ func (this APIRequestUtils) Request(
 		case *[]byte:
 			tmp, err := ioutil.ReadAll(resp.Body)
 			if err != nil {
-				result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %s", url, string(tmp), err)
+				result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %w", url, string(tmp), err)
 				resp.Body.Close()
 				return
 			}
@@ -282,7 +282,7 @@ func (this APIRequestUtils) Request(
 			json_err := json.NewDecoder(resp.Body).Decode(expected_response_struct)
 			if json_err != nil {
 				body, _ := ioutil.ReadAll(resp.Body)
-				result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %s", url, string(body), json_err)
+				result.Errorf("Bad response.Body returned for url: %s response.Body: %v, err: %w", url, string(body), json_err)
 				resp.Body.Close()
 				return
 			}
@@ -0,0 +1,239 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ConfigErrors is the aggregated error returned by LoadInto, one entry per missing or unparsable key
+type ConfigErrors []error
+
+func (this ConfigErrors) Error() string {
+	lines := make([]string, len(this))
+	for i, err := range this {
+		lines[i] = "\t- " + err.Error()
+	}
+	return fmt.Sprintf("%d config error(s):\n%s", len(this), strings.Join(lines, "\n"))
+}
+
+//Unwrap returns the error for each key, so errors.Is and errors.As (from Go 1.20 on) check all of them
+func (this ConfigErrors) Unwrap() []error {
+	return append([]error{}, this...)
+}
+
+/*
+	Is reports whether any of the errors matches target, e.g. CONFIG_VAR_NOT_FOUND_ERR. errors.Is only follows
+	Unwrap() []error from Go 1.20 on, so ConfigErrors matches its errors itself.
+*/
+func (this ConfigErrors) Is(target error) bool {
+	for _, err := range this {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+//As finds the first of the errors that matches target, e.g. a *ConfigError, and sets target to it. See Is.
+func (this ConfigErrors) As(target interface{}) bool {
+	for _, err := range this {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+var duration_type = reflect.TypeOf(time.Duration(0))
+
+/*
+	LoadInto fills the fields of the struct that target points to from the given IConfigGetter. Fields are bound with
+	struct tags:
+		config:"KEY"       The config var to load into the field
+		default:"value"    The value to use if the config var is not found
+		required:"true"    The config var must be found (or have a default), otherwise it is reported as an error
+		prefix:"DB_"       On a nested struct (or *struct) field, prepended to the keys of all of its fields
+
+	Supported field types are string, bool, the int, uint and float kinds, time.Duration and []string. Values are
+	parsed the same way as the typed getters on IConfigGetter. Fields without a config tag are left untouched.
+
+	Example:
+		type JaegerConfig struct {
+			ServiceName string `config:"SERVICE_NAME" default:"svc"`
+			Endpoint    string `config:"COLLECTOR_ENDPOINT" required:"true"`
+		}
+		type ServiceConfig struct {
+			Port   int           `config:"PORT" default:"8080"`
+			Jaeger JaegerConfig  `prefix:"JAEGER_"`
+		}
+
+	@params
+		cfg IConfigGetter The config getter to load values from
+		target interface{} A pointer to the struct to fill
+	@returns
+		error nil if all went well, otherwise a ConfigErrors listing every missing or unparsable key
+*/
+func LoadInto(cfg IConfigGetter, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("LoadInto target must be a non-nil pointer to a struct")
+	}
+
+	errs := ConfigErrors{}
+	loadStruct(cfg, value.Elem(), "", &errs)
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+//loadStruct binds each tagged field of the struct value, recursing into nested structs
+func loadStruct(cfg IConfigGetter, value reflect.Value, prefix string, errs *ConfigErrors) {
+	value_type := value.Type()
+	for i := 0; i < value_type.NumField(); i++ {
+		field := value_type.Field(i)
+		field_value := value.Field(i)
+		if field.PkgPath != "" { //Unexported
+			continue
+		}
+
+		key, has_key := field.Tag.Lookup("config")
+		if !has_key {
+			if field.Type.Kind() == reflect.Struct {
+				loadStruct(cfg, field_value, prefix+field.Tag.Get("prefix"), errs)
+			} else if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+				if field_value.IsNil() {
+					field_value.Set(reflect.New(field.Type.Elem()))
+				}
+				loadStruct(cfg, field_value.Elem(), prefix+field.Tag.Get("prefix"), errs)
+			}
+			continue
+		}
+
+		key = prefix + key
+		found, err := loadField(cfg, key, field_value)
+		if err != nil {
+			*errs = append(*errs, err)
+			continue
+		}
+		if found {
+			continue
+		}
+
+		if default_value, has_default := field.Tag.Lookup("default"); has_default {
+			if err := setFieldFromString(field_value, default_value); err != nil {
+				*errs = append(*errs, parseError(key, configValue{value: default_value, source: "default tag"}, err))
+			}
+		} else if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+			*errs = append(*errs, notFoundError(key))
+		}
+	}
+}
+
+/*
+	loadField loads the config var key into the field using the lookup that matches the field's type
+	@returns
+		bool True if the config var was found
+		error A *ConfigError if the value could not be parsed, or the field type is not supported
+*/
+func loadField(cfg IConfigGetter, key string, field reflect.Value) (bool, error) {
+	switch {
+	case field.Type() == duration_type:
+		value, found, err := cfg.LookupDuration(key)
+		if found && err == nil {
+			field.SetInt(int64(value))
+		}
+		return found, err
+	case field.Kind() == reflect.String:
+		value, found, err := cfg.LookupString(key)
+		if found && err == nil {
+			field.SetString(value)
+		}
+		return found, err
+	case field.Kind() == reflect.Bool:
+		value, found, err := cfg.LookupBool(key)
+		if found && err == nil {
+			field.SetBool(value)
+		}
+		return found, err
+	case field.Kind() >= reflect.Int && field.Kind() <= reflect.Int64:
+		value, found, err := cfg.LookupInt(key)
+		if found && err == nil {
+			if field.OverflowInt(int64(value)) {
+				return true, &ConfigError{Key: key, Value: strconv.Itoa(value), Err: strconv.ErrRange}
+			}
+			field.SetInt(int64(value))
+		}
+		return found, err
+	case field.Kind() >= reflect.Uint && field.Kind() <= reflect.Uint64:
+		value, found, err := cfg.LookupInt(key)
+		if found && err == nil {
+			if value < 0 || field.OverflowUint(uint64(value)) {
+				return true, &ConfigError{Key: key, Value: strconv.Itoa(value), Err: strconv.ErrRange}
+			}
+			field.SetUint(uint64(value))
+		}
+		return found, err
+	case field.Kind() == reflect.Float32 || field.Kind() == reflect.Float64:
+		value, found, err := cfg.LookupFloat(key)
+		if found && err == nil {
+			field.SetFloat(value)
+		}
+		return found, err
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		value, found, err := cfg.LookupStringSlice(key)
+		if found && err == nil {
+			field.Set(reflect.ValueOf(value).Convert(field.Type()))
+		}
+		return found, err
+	default:
+		return false, &ConfigError{Key: key, Err: fmt.Errorf("unsupported field type %s", field.Type())}
+	}
+}
+
+//setFieldFromString parses a default tag value into the field
+func setFieldFromString(field reflect.Value, raw string) error {
+	switch {
+	case field.Type() == duration_type:
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(value))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case field.Kind() >= reflect.Int && field.Kind() <= reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case field.Kind() >= reflect.Uint && field.Kind() <= reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(value)
+	case field.Kind() == reflect.Float32 || field.Kind() == reflect.Float64:
+		value, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(splitConfigList(raw)).Convert(field.Type()))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package common
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+/*
+	Config values (from files or environment variables) can be encrypted so that credentials can be committed, e.g. in
+	config.json.dist. An encrypted value looks like:
+		enc:v1:<base64 of the AES-GCM nonce followed by the sealed value>
+	The values in config files are decrypted when the files are loaded, using the master key from the
+	CONFIG_MASTER_KEY environment variable (base64), or from the file named by CONFIG_MASTER_KEY_FILE. Decrypted values
+	are flagged as secrets (see IsSecret). A value that can't be decrypted is reported as a *ConfigError for its key by
+	the typed getters, and in GetConfigReport. Use cmd/configcrypt to encrypt, decrypt and rotate values in a file.
+*/
+const CONFIG_ENCRYPTED_PREFIX = "enc:v1:"
+const CONFIG_MASTER_KEY_ENV_VAR = "CONFIG_MASTER_KEY"
+const CONFIG_MASTER_KEY_FILE_ENV_VAR = "CONFIG_MASTER_KEY_FILE"
+
+var CONFIG_MASTER_KEY_NOT_SET_ERR error = errors.New("no master key, set " + CONFIG_MASTER_KEY_ENV_VAR + " or " + CONFIG_MASTER_KEY_FILE_ENV_VAR)
+
+/*
+	LoadConfigMasterKey loads the master key used to decrypt config values, from CONFIG_MASTER_KEY or the file named by
+	CONFIG_MASTER_KEY_FILE
+	@returns
+		[]byte The 16, 24 or 32 byte AES key
+		error CONFIG_MASTER_KEY_NOT_SET_ERR if neither is set, or an error if the key is invalid
+*/
+func LoadConfigMasterKey() ([]byte, error) {
+	if encoded := os.Getenv(CONFIG_MASTER_KEY_ENV_VAR); encoded != "" {
+		return ParseConfigMasterKey(encoded)
+	}
+	if key_file := os.Getenv(CONFIG_MASTER_KEY_FILE_ENV_VAR); key_file != "" {
+		contents, err := ioutil.ReadFile(key_file)
+		if err != nil {
+			return nil, err
+		}
+		return ParseConfigMasterKey(string(contents))
+	}
+	return nil, CONFIG_MASTER_KEY_NOT_SET_ERR
+}
+
+//ParseConfigMasterKey decodes a base64 encoded master key and checks that it is a valid AES key length
+func ParseConfigMasterKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("master key is not valid base64")
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, errors.New("master key must be 16, 24 or 32 bytes")
+	}
+	return key, nil
+}
+
+//GenerateConfigMasterKey returns a new random 32 byte master key, base64 encoded
+func GenerateConfigMasterKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+/*
+	EncryptConfigValue encrypts a config value with the master key
+	@params
+		key []byte The master key, see ParseConfigMasterKey
+		plaintext string The value to encrypt
+	@returns
+		string The encrypted value, beginning with CONFIG_ENCRYPTED_PREFIX
+		error nil if all went well
+*/
+func EncryptConfigValue(key []byte, plaintext string) (string, error) {
+	gcm, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return CONFIG_ENCRYPTED_PREFIX + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+/*
+	DecryptConfigValue decrypts a value made by EncryptConfigValue
+	@params
+		key []byte The master key, see ParseConfigMasterKey
+		value string The encrypted value, beginning with CONFIG_ENCRYPTED_PREFIX
+	@returns
+		string The decrypted value
+		error nil if all went well
+*/
+func DecryptConfigValue(key []byte, value string) (string, error) {
+	if !strings.HasPrefix(value, CONFIG_ENCRYPTED_PREFIX) {
+		return "", errors.New("encrypted values must begin with " + CONFIG_ENCRYPTED_PREFIX)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, CONFIG_ENCRYPTED_PREFIX))
+	if err != nil {
+		return "", errors.New("encrypted value is not valid base64")
+	}
+
+	gcm, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("could not decrypt value, it may have been encrypted with a different master key")
+	}
+	return string(plaintext), nil
+}
+
+func newConfigCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//decryptConfigValue decrypts an encrypted config value with the master key from the environment
+func decryptConfigValue(value string) (string, error) {
+	key, err := LoadConfigMasterKey()
+	if err != nil {
+		return "", err
+	}
+	return DecryptConfigValue(key, value)
+}
+
+//decryptConfigValues decrypts the encrypted values of freshly loaded config files, caching the results (or errors)
+func (this *configGetter) decryptConfigValues(config_data map[string]string) {
+	for _, raw := range config_data {
+		if strings.HasPrefix(raw, CONFIG_ENCRYPTED_PREFIX) {
+			this.resolveReference(raw)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+	parseConfigFile parses the contents of a config file into a map of config values. The format is chosen by the
+	file's extension, ignoring a trailing ".dist":
+		.json (or any unknown extension)  A JSON object. Lines beginning with '##' are comments and are ignored.
+		.yaml, .yml                       A YAML mapping
+		.toml                             A TOML document
+		.env                              KEY=VALUE lines. Lines beginning with '#' are comments, an "export " prefix
+		                                  is allowed, and values may be single or double quoted.
+	Numbers, booleans and dates are converted to strings. Nested objects are flattened into dotted keys, so
+	{"db": {"pool": {"max": 10}}} is loaded as "db.pool.max" = "10". Arrays of scalars are stored as a JSON array of
+	strings, which the list getters (e.g. GetStringSlice) understand. Any other array is stored as its JSON.
+	@params
+		config_file string The path of the file, used to pick the format
+		contents []byte The contents of the file
+	@returns
+		map[string]string The config values in the file
+		error nil if all went well
+*/
+func parseConfigFile(config_file string, contents []byte) (map[string]string, error) {
+	return parseConfigFormat(ConfigFileFormat(config_file), contents)
+}
+
+/*
+	parseConfigFormat parses the contents of a config file in the given format, as returned by ConfigFileFormat. The
+	layer files of a config file (e.g. ".env.local" for ".env") are parsed in the format of the config file.
+*/
+func parseConfigFormat(format string, contents []byte) (map[string]string, error) {
+	switch format {
+	case ".yaml", ".yml":
+		return parseYamlConfig(contents)
+	case ".toml":
+		return parseTomlConfig(contents)
+	case ".env":
+		return parseDotEnvConfig(contents)
+	default:
+		return parseJsonConfig(contents)
+	}
+}
+
+/*
+	ConfigFileFormat returns the lower case extension of config_file, ignoring a trailing ".dist", e.g. ".json" for
+	"config/config.json.dist". It's the extension parseConfigFile picks the format by.
+*/
+func ConfigFileFormat(config_file string) string {
+	return strings.ToLower(filepath.Ext(strings.TrimSuffix(config_file, ".dist")))
+}
+
+/*
+	ParseConfigFile parses the contents of a config file the same way the config getter does, see parseConfigFile.
+	It's exported for tools that check config files, like cmd/configlint.
+*/
+func ParseConfigFile(config_file string, contents []byte) (map[string]string, error) {
+	return parseConfigFile(config_file, contents)
+}
+
+/*
+	StripConfigComments blanks out the '##' comment lines of a JSON config file, leaving plain JSON. The lines are
+	blanked rather than removed so that line numbers still match the original file.
+*/
+func StripConfigComments(contents []byte) string {
+	all_lines := strings.Split(string(contents), "\n")
+	for i, line := range all_lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "##") {
+			all_lines[i] = ""
+		}
+	}
+	return strings.Join(all_lines, "\n")
+}
+
+func parseJsonConfig(contents []byte) (map[string]string, error) {
+	json_data := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(StripConfigComments(contents)))
+	decoder.UseNumber() //Keep numbers as they were written
+	if err := decoder.Decode(&json_data); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON object at offset %d", decoder.InputOffset())
+	}
+	return flattenConfigValues(json_data)
+}
+
+func parseYamlConfig(contents []byte) (map[string]string, error) {
+	yaml_data := map[string]interface{}{}
+	if err := yaml.Unmarshal(contents, &yaml_data); err != nil {
+		return nil, err
+	}
+	return flattenConfigValues(yaml_data)
+}
+
+func parseTomlConfig(contents []byte) (map[string]string, error) {
+	toml_data := map[string]interface{}{}
+	if _, err := toml.Decode(string(contents), &toml_data); err != nil {
+		return nil, err
+	}
+	return flattenConfigValues(toml_data)
+}
+
+func parseDotEnvConfig(contents []byte) (map[string]string, error) {
+	file_data := map[string]string{}
+	for i, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		separator := strings.Index(line, "=")
+		if separator < 1 {
+			return nil, fmt.Errorf("line %d is not in KEY=VALUE format", i+1)
+		}
+		key := strings.TrimSpace(line[:separator])
+		value := strings.TrimSpace(line[separator+1:])
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d has an invalid quoted value: %v", i+1, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			//Unquoted values may have a trailing comment
+			if comment := strings.Index(value, " #"); comment != -1 {
+				value = strings.TrimSpace(value[:comment])
+			}
+		}
+		file_data[key] = value
+	}
+	return file_data, nil
+}
+
+//flattenConfigValues converts a decoded JSON, YAML or TOML document into config values. See parseConfigFile.
+func flattenConfigValues(data map[string]interface{}) (map[string]string, error) {
+	file_data := map[string]string{}
+	for key, value := range data {
+		if err := flattenConfigValue(key, value, file_data); err != nil {
+			return nil, err
+		}
+	}
+	return file_data, nil
+}
+
+//flattenConfigValue adds value to file_data under key, recursing into nested objects
+func flattenConfigValue(key string, value interface{}, file_data map[string]string) error {
+	switch typed_value := value.(type) {
+	case map[string]interface{}:
+		for nested_key, nested_value := range typed_value {
+			if err := flattenConfigValue(key+"."+nested_key, nested_value, file_data); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}: //YAML mappings
+		for nested_key, nested_value := range typed_value {
+			if err := flattenConfigValue(key+"."+fmt.Sprint(nested_key), nested_value, file_data); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, len(typed_value))
+		for i, item := range typed_value {
+			string_item, ok := stringifyConfigValue(item)
+			if !ok { //Not a list of scalars, keep the whole array as JSON
+				json_value, err := json.Marshal(jsonCompatible(typed_value))
+				if err != nil {
+					return fmt.Errorf("the value of %s could not be converted to JSON: %v", key, err)
+				}
+				file_data[key] = string(json_value)
+				return nil
+			}
+			items[i] = string_item
+		}
+		json_value, _ := json.Marshal(items)
+		file_data[key] = string(json_value)
+	case []map[string]interface{}: //TOML arrays of tables
+		json_value, err := json.Marshal(jsonCompatible(typed_value))
+		if err != nil {
+			return fmt.Errorf("the value of %s could not be converted to JSON: %v", key, err)
+		}
+		file_data[key] = string(json_value)
+	default:
+		string_value, ok := stringifyConfigValue(value)
+		if !ok {
+			return fmt.Errorf("the value of %s has an unsupported type %T", key, value)
+		}
+		file_data[key] = string_value
+	}
+	return nil
+}
+
+//jsonCompatible converts YAML mappings (which have interface{} keys) so that they can be marshaled to JSON
+func jsonCompatible(value interface{}) interface{} {
+	switch typed_value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, nested_value := range typed_value {
+			converted[fmt.Sprint(key)] = jsonCompatible(nested_value)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := map[string]interface{}{}
+		for key, nested_value := range typed_value {
+			converted[key] = jsonCompatible(nested_value)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(typed_value))
+		for i, item := range typed_value {
+			converted[i] = jsonCompatible(item)
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+//stringifyConfigValue converts a scalar value to its config string. Returns false if the value is not a scalar.
+func stringifyConfigValue(value interface{}) (string, bool) {
+	switch typed_value := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return typed_value, true
+	case bool:
+		return strconv.FormatBool(typed_value), true
+	case int, int64, uint64, json.Number:
+		return fmt.Sprint(typed_value), true
+	case float64:
+		return strconv.FormatFloat(typed_value, 'f', -1, 64), true
+	case time.Time:
+		return typed_value.Format(time.RFC3339), true
+	default:
+		return "", false
+	}
+}
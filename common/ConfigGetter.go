@@ -1,25 +1,40 @@
 package common
 
 import (
-	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
-var config_map_lock sync.RWMutex
-var json_data = map[string]string{}
-var config_loaded = false
-var defaults_loaded = false
-
 /*
-	This class is used for loading configuration values. It supports a three tier setup with precedences.
-	See this.MustGetConfigVar for more information.
+	This class is used for loading configuration values. It supports a layered setup with precedences.
+	See this.MustGetConfigVar and ConfigLayers.go for more information.
 */
 type IConfigGetter interface {
 	MustGetConfigVar(variableName string) string
 	SafeGetConfigVar(variableName string) string
+	LookupConfigVar(variableName string) (string, bool)
+	GetString(variableName string) (string, error)
+	GetInt(variableName string) (int, error)
+	GetBool(variableName string) (bool, error)
+	GetFloat(variableName string) (float64, error)
+	GetDuration(variableName string) (time.Duration, error)
+	GetStringSlice(variableName string) ([]string, error)
+	LookupString(variableName string) (string, bool, error)
+	LookupInt(variableName string) (int, bool, error)
+	LookupBool(variableName string) (bool, bool, error)
+	LookupFloat(variableName string) (float64, bool, error)
+	LookupDuration(variableName string) (time.Duration, bool, error)
+	LookupStringSlice(variableName string) ([]string, bool, error)
+	Reload() error
+	Watch(interval time.Duration) func()
+	Subscribe(variableName string, callback func(old_value string, new_value string))
+	IsSecret(variableName string) bool
+	GetConfigSource(variableName string) string
+	GetConfigReport() []ConfigEntry
 }
 
 //Implements the IConfigGetter interface
@@ -35,110 +50,287 @@ type configGetter struct {
 				"key2" : "value2",
 				"key3" : "value3"
 			}
+		YAML (.yaml/.yml), TOML (.toml) and dotenv (.env) files are also supported, see ConfigFormats.go
 	*/
-	config_file_path string
+	config_file_path   string
+	profile_env_var    string            //The environment variable that names the active profile, see ConfigLayers.go
+	flag_data          map[string]string //Values from command-line flags, see WithCommandLineFlags
+	sources            []ConfigSource    //Layers of values above the config files, see WithConfigSources
+	interpolation      bool              //True if ${...} expressions in values are interpolated, see WithConfigInterpolation
+	references_enabled bool              //True if file:, env: and base64: references are resolved, see WithConfigReferences
+
+	load_lock     sync.Mutex          //Held while the config layers are read, so a slow source only blocks other loads
+	source_values []map[string]string //The last values loaded from each source, see loadConfigSources
+
+	lock           sync.RWMutex
+	loaded         bool              //True once the config files have been read
+	config_data    map[string]string //The merged values of the config files
+	config_sources map[string]string //The path of the config file that supplied each value in config_data
+
+	subscription_lock sync.Mutex
+	subscriptions     map[string][]func(string, string) //Callbacks registered with Subscribe, by config var
+
+	reference_lock sync.Mutex
+	references     map[string]resolvedReference //The cached results of resolving references, by raw value
+
+	requested_keys sync.Map //Every config var that has been looked up, for GetConfigReport
 }
 
 /*
 	GetConfigGetter is the factory method to create a configGetter with injected information.
 	@params
-		config_file_path string This is the local file path to the .json file that holds your config json.
+		config_file_path string This is the local file path to the .json (or .yaml, .yml, .toml, .env) config file.
+		options ...ConfigOpt Optional customizations, see ConfigLayers.go
 */
-func GetConfigGetter(config_file_path string) IConfigGetter {
-	return &configGetter{
+func GetConfigGetter(config_file_path string, options ...ConfigOpt) IConfigGetter {
+	c := &configGetter{
 		config_file_path: config_file_path,
+		profile_env_var:  DEFAULT_CONFIG_PROFILE_ENV_VAR,
+		flag_data:        map[string]string{},
+	}
+	for _, opt := range options {
+		c = opt(c)
 	}
+	return c
 }
 
 /*
 	GetConfigVar Loads a configuration value. If a value is not found, a panic is thrown
 	Config precedence, highest to lowest:
-	- Environment variables
+	- Command-line flags, if WithCommandLineFlags was used
+	- Environment variables. Nested keys like "db.pool.max" can also be set with their mapped name, DB_POOL_MAX
+	- Config sources, if WithConfigSources was used, see ConfigSources.go
+	- Values of <config_file_path> local overrides, e.g. config/config.local.json
 	- Values of <config_file_path>
+	- Values of <config_file_path> for the profile named by APP_ENV, e.g. config/config.production.json
 	- Values of <config_file_path>.dist
-	Empty strings are considered to be unset. Do not use them as permitted values.
+	Empty strings are considered to be unset. Do not use them as permitted values, or use LookupConfigVar instead.
+	With WithConfigInterpolation, values can include other config vars with ${OTHER_KEY}, see ConfigInterpolation.go.
+	With WithConfigReferences, they can also be references to files, other environment variables or base64 encoded
+	values, see ConfigSecrets.go.
+	If a value can't be interpolated or its reference can't be resolved a panic is thrown.
 
 	@params
 		variableName string The string value in the json file or environment variable name of the config to load
 	@returns
 		string The value found for the variableName of highest precedence found
 */
-func (this configGetter) MustGetConfigVar(variableName string) string {
-	config := os.Getenv(variableName)
-	if config == "" {
-		this.loadDefaults()
-		this.loadConfig()
-		ok := false
-		config_map_lock.Lock()
-		config, ok = json_data[variableName]
-		config_map_lock.Unlock()
-		if !ok {
-			panic("FATAL ERROR COULD NOT LOAD VAR : " + variableName)
-		}
+func (this *configGetter) MustGetConfigVar(variableName string) string {
+	config, found, err := this.lookupResolved(variableName)
+	if err != nil && !found {
+		panic("FATAL ERROR " + err.Error())
+	}
+	if !found {
+		panic("FATAL ERROR COULD NOT LOAD VAR : " + variableName)
 	}
-	return config
+	if err != nil {
+		panic("FATAL ERROR COULD NOT RESOLVE VAR : " + err.Error())
+	}
+	return config.value
 }
 
 /*
-	Same as this.MustGetConfigVar, but returns an empty string if the config is not found instead of panicking
+	Same as this.MustGetConfigVar, but returns an empty string if the config is not found (or can't be resolved)
+	instead of panicking. Like MustGetConfigVar, it still panics if the config files can't be loaded.
 */
-func (this configGetter) SafeGetConfigVar(variableName string) string {
-	config := os.Getenv(variableName)
-	if config == "" {
-		this.loadDefaults()
-		this.loadConfig()
-		ok := false
-		config_map_lock.Lock()
-		config, ok = json_data[variableName]
-		config_map_lock.Unlock()
-		if !ok {
-			return ""
+func (this *configGetter) SafeGetConfigVar(variableName string) string {
+	config, found, err := this.lookupResolved(variableName)
+	if err != nil && !found {
+		panic("FATAL ERROR " + err.Error())
+	}
+	if err != nil {
+		return ""
+	}
+	return config.value
+}
+
+/*
+	LookupConfigVar loads a configuration value with the same precedence as MustGetConfigVar, except that empty strings
+	are treated as set, like os.LookupEnv does. An environment variable explicitly set to "" (or an empty value in a
+	config file) is returned as "" instead of falling through to the next tier, so a value can be deliberately blanked.
+	@params
+		variableName string The string value in the json file or environment variable name of the config to load
+	@returns
+		string The value found for the variableName of highest precedence found
+		bool False if the value is not present in any tier, or it can't be resolved (LookupString reports why)
+*/
+func (this *configGetter) LookupConfigVar(variableName string) (string, bool) {
+	raw, _, found, err := this.lookupTiers(variableName, true)
+	if err != nil {
+		panic("FATAL ERROR " + err.Error())
+	}
+	if !found {
+		return "", false
+	}
+	config, err := this.resolveValue(raw, []string{variableName})
+	if err != nil {
+		return "", false
+	}
+	return config.value, true
+}
+
+/*
+	lookup finds the value of highest precedence for variableName, along with the tier that supplied it.
+	@returns
+		string The value found, or an empty string
+		string CONFIG_SOURCE_FLAGS, CONFIG_SOURCE_ENV, or the path of the config file the value was read from
+		bool True if a value was found in any tier
+		error nil unless the config files had to be loaded and couldn't be, see loadConfigs
+*/
+func (this *configGetter) lookup(variableName string) (string, string, bool, error) {
+	return this.lookupTiers(variableName, false)
+}
+
+//lookupTiers is lookup, but if keep_empty is set empty environment variables are returned instead of being skipped
+func (this *configGetter) lookupTiers(variableName string, keep_empty bool) (string, string, bool, error) {
+	this.requested_keys.LoadOrStore(variableName, true)
+	if config, ok := this.flag_data[variableName]; ok {
+		return config, CONFIG_SOURCE_FLAGS, true, nil
+	}
+	if config, ok := lookupEnv(variableName, keep_empty); ok {
+		return config, CONFIG_SOURCE_ENV, true, nil
+	}
+	if strings.Contains(variableName, ".") {
+		if config, ok := lookupEnv(configEnvVarName(variableName), keep_empty); ok {
+			return config, CONFIG_SOURCE_ENV, true, nil
 		}
 	}
-	return config
+
+	if err := this.loadConfigs(); err != nil {
+		return "", "", false, err
+	}
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	config, ok := this.config_data[variableName]
+	if !ok {
+		return "", "", false, nil
+	}
+	return config, this.config_sources[variableName], true, nil
 }
 
-// loadConfig Loads the overridden configuration values, if not yet done
-func (this configGetter) loadConfig() {
-	if !config_loaded {
-		loadConfigFile(this.config_file_path)
-		config_loaded = true
+/*
+	Reload re-reads the config files (and sources, see ConfigSource). The new values are only swapped in if all of the
+	files could be read and parsed, otherwise the previously loaded values are kept and the error is returned.
+	Callbacks registered with Subscribe are called for any config var whose value changed.
+*/
+func (this *configGetter) Reload() error {
+	if err := this.loadConfigs(); err != nil { //So the values before the reload can be compared with the new ones
+		return err
+	}
+	old_values := this.subscribedValues()
+	this.load_lock.Lock()
+	config_data, config_sources, err := this.readConfigLayers()
+	if err != nil {
+		this.load_lock.Unlock()
+		return err
 	}
+	this.lock.Lock()
+	this.config_data = config_data
+	this.config_sources = config_sources
+	this.loaded = true
+	this.lock.Unlock()
+	this.load_lock.Unlock()
+	this.clearResolvedReferences()
+	this.decryptConfigValues(config_data)
+
+	this.notifySubscribers(old_values)
+	return nil
 }
 
-// loadDefaults Loads the default configuration values, if not yet done
-func (this configGetter) loadDefaults() {
-	if !defaults_loaded {
-		loadConfigFile(this.config_file_path + ".dist")
-		defaults_loaded = true
+/*
+	loadConfigs Loads the configuration files (and sources) exactly once. A file that can't be read or parsed is
+	returned as an error, and loading is tried again on the next read. A config source that can't be loaded is skipped,
+	see ConfigSource.
+*/
+func (this *configGetter) loadConfigs() error {
+	if this.isLoaded() {
+		return nil
 	}
+
+	//The layers are read without holding lock, so reads of the environment aren't blocked by a slow config source
+	this.load_lock.Lock()
+	defer this.load_lock.Unlock()
+	if this.isLoaded() { //Another goroutine may have loaded them while we waited for the lock
+		return nil
+	}
+	config_data, config_sources, err := this.readConfigLayers()
+	if err != nil {
+		return err
+	}
+
+	this.lock.Lock()
+	this.config_data = config_data
+	this.config_sources = config_sources
+	this.loaded = true
+	this.lock.Unlock()
+	this.decryptConfigValues(config_data)
+	return nil
 }
 
-// loadConfigFile Loads json contents of a config file into json_data
-// If the file doesn't exist, it is ignored. The file contents must be a JSON object with only string properties.
-// Comment lines are ones that begin with '##', and they will be ignored along with empty lines
-// config_file: The path to the JSON file to load
-func loadConfigFile(config_file string) {
-	if _, err := os.Stat(config_file); err == nil {
-		reader_result, read_err := ioutil.ReadFile(config_file)
-		if read_err != nil {
-			panic("FATAL ERROR COULD NOT LOAD CONFIG FILE " + config_file + " Err: " + read_err.Error())
-		}
+func (this *configGetter) isLoaded() bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.loaded
+}
 
-		//Omit any line that begins with '##'
-		all_lines := strings.Split(string(reader_result), "\n")
-		cleaned_file_string := ""
-		for _, line := range all_lines {
-			if !strings.HasPrefix(strings.TrimSpace(line), "##") {
-				cleaned_file_string += line + "\n"
-			}
-		}
+//lookupEnv returns the value of an environment variable. Empty values are treated as unset unless keep_empty is set.
+func lookupEnv(name string, keep_empty bool) (string, bool) {
+	config, ok := os.LookupEnv(name)
+	return config, ok && (keep_empty || config != "")
+}
 
-		config_map_lock.Lock()
-		json_err := json.Unmarshal([]byte(cleaned_file_string), &json_data)
-		config_map_lock.Unlock()
-		if json_err != nil {
-			panic("FATAL ERROR COULD NOT UNMARSHAL CONFIG FILE " + config_file + " Err: " + json_err.Error())
+/*
+	configEnvVarName returns the environment variable that can override a nested config key. Dots and dashes become
+	underscores and the name is upper cased, so "db.pool.max" can be overridden with DB_POOL_MAX. It's only used for
+	dotted keys, so a flat key like "home" isn't overridden by $HOME.
+*/
+func configEnvVarName(variableName string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(variableName))
+}
+
+/*
+	readConfigFiles reads each of the config files in order, with later files overriding the values of earlier ones
+	@params
+		config_files []string The paths of the config files, lowest precedence first
+		format string The format to parse every file in, see ConfigFileFormat
+	@returns
+		map[string]string The merged config values
+		map[string]string The path of the file that supplied each value
+		error nil if all went well
+*/
+func readConfigFiles(config_files []string, format string) (map[string]string, map[string]string, error) {
+	config_data := map[string]string{}
+	config_sources := map[string]string{}
+	for _, config_file := range config_files {
+		file_data, err := loadConfigFile(config_file, format)
+		if err != nil {
+			return nil, nil, err
 		}
+		for key, value := range file_data {
+			config_data[key] = value
+			config_sources[key] = config_file
+		}
+	}
+	return config_data, config_sources, nil
+}
+
+// loadConfigFile Loads the contents of a config file, see parseConfigFormat.
+// If the file doesn't exist, it is ignored.
+// config_file: The path to the config file to load
+// format: The format of the file, which for a layer file like config.local.json is the format of config.json
+func loadConfigFile(config_file string, format string) (map[string]string, error) {
+	if _, err := os.Stat(config_file); err != nil {
+		return map[string]string{}, nil
+	}
+
+	reader_result, read_err := ioutil.ReadFile(config_file)
+	if read_err != nil {
+		return nil, errors.New("COULD NOT LOAD CONFIG FILE " + config_file + " Err: " + read_err.Error())
+	}
+
+	file_data, parse_err := parseConfigFormat(format, reader_result)
+	if parse_err != nil {
+		return nil, errors.New("COULD NOT UNMARSHAL CONFIG FILE " + config_file + " Err: " + parse_err.Error())
 	}
+	return file_data, nil
 }
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+	When the getter is made with WithConfigInterpolation, config values (from files or environment variables) can
+	include other config vars:
+		${OTHER_KEY}             The value of OTHER_KEY. It is an error if OTHER_KEY is not set.
+		${OTHER_KEY:-default}    The value of OTHER_KEY, or "default" if it is not set or empty
+		$${                      A literal "${"
+	OTHER_KEY is loaded with the same precedence as any other config var, so "${API_HOST}/users" uses API_HOST from the
+	environment if it is set there. Interpolation happens before references (see ConfigSecrets.go) are resolved, so with
+	WithConfigReferences too "file:${SECRETS_DIR}/jwt_private" works. A value that (indirectly) includes itself is reported as an error that
+	wraps CONFIG_INTERPOLATION_CYCLE_ERR.
+*/
+
+var CONFIG_INTERPOLATION_CYCLE_ERR error = errors.New("interpolation cycle")
+
+/*
+	WithConfigInterpolation turns on the interpolation of ${...} expressions in config values. It is off by default, so
+	existing values that contain "${" are returned as they are.
+*/
+func WithConfigInterpolation() ConfigOpt {
+	return func(c *configGetter) *configGetter {
+		c.interpolation = true
+		return c
+	}
+}
+
+/*
+	interpolate replaces the ${...} expressions in raw with the values they refer to
+	@params
+		raw string The value to interpolate
+		chain []string The config vars being resolved, outermost first. Used to detect cycles.
+	@returns
+		string The interpolated value
+		bool True if any of the included values is a secret
+		error nil if all went well
+*/
+func (this *configGetter) interpolate(raw string, chain []string) (string, bool, error) {
+	if !this.interpolation || !strings.Contains(raw, "${") {
+		return raw, false, nil
+	}
+
+	interpolated := strings.Builder{}
+	secret := false
+	for i := 0; i < len(raw); {
+		if strings.HasPrefix(raw[i:], "$${") {
+			interpolated.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(raw[i:], "${") {
+			interpolated.WriteByte(raw[i])
+			i++
+			continue
+		}
+
+		end := strings.Index(raw[i:], "}")
+		if end == -1 {
+			return "", false, fmt.Errorf("unterminated ${ at position %d", i)
+		}
+		expression := raw[i+2 : i+end]
+		name, default_value, has_default := expression, "", false
+		if separator := strings.Index(expression, ":-"); separator != -1 {
+			name, default_value, has_default = expression[:separator], expression[separator+2:], true
+		}
+
+		value, value_secret, err := this.interpolatedValue(strings.TrimSpace(name), default_value, has_default, chain)
+		if err != nil {
+			return "", false, err
+		}
+		interpolated.WriteString(value)
+		secret = secret || value_secret
+		i += end + 1
+	}
+	return interpolated.String(), secret, nil
+}
+
+//interpolatedValue returns the resolved value of the config var name for use in an ${...} expression
+func (this *configGetter) interpolatedValue(
+	name string,
+	default_value string,
+	has_default bool,
+	chain []string,
+) (string, bool, error) {
+	for _, key := range chain {
+		if key == name {
+			return "", false, fmt.Errorf("%w: %s -> %s", CONFIG_INTERPOLATION_CYCLE_ERR, strings.Join(chain, " -> "), name)
+		}
+	}
+
+	raw, _, found, err := this.lookup(name)
+	if err != nil {
+		return "", false, err
+	}
+	if !found || (raw == "" && has_default) {
+		if has_default {
+			return default_value, false, nil
+		}
+		return "", false, fmt.Errorf("${%s} is not set", name)
+	}
+
+	config, err := this.resolveValue(raw, append(append([]string{}, chain...), name))
+	if err != nil {
+		return "", false, err
+	}
+	return config.value, config.secret, nil
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+	A configGetter reads its values from an ordered list of layers. From lowest to highest precedence:
+		<config_file_path>.dist          e.g. config/config.json.dist
+		<config_file_path> for a profile e.g. config/config.production.json, when APP_ENV=production
+		<config_file_path>               e.g. config/config.json
+		<config_file_path> local file    e.g. config/config.local.json, for overrides that aren't committed
+		Config sources                   Only when WithConfigSources is used, see ConfigSources.go
+		Environment variables
+		Command-line flags               Only when WithCommandLineFlags is used
+	Missing files are skipped. GetConfigSource reports which layer supplied a config var.
+*/
+
+//The environment variable that chooses the active profile, unless WithProfileEnvVar is used
+const DEFAULT_CONFIG_PROFILE_ENV_VAR = "APP_ENV"
+
+//The source reported for values that were read from command-line flags
+const CONFIG_SOURCE_FLAGS = "flags"
+
+//ConfigOpt's are wrapper functions that customize a configGetter when it is made with GetConfigGetter
+type ConfigOpt func(*configGetter) *configGetter
+
+//WithProfileEnvVar chooses the environment variable that holds the name of the active profile
+func WithProfileEnvVar(profile_env_var string) ConfigOpt {
+	return func(c *configGetter) *configGetter {
+		c.profile_env_var = profile_env_var
+		return c
+	}
+}
+
+/*
+	WithCommandLineFlags adds a layer of config values from command-line arguments, which takes precedence over
+	everything else. Arguments in the form --KEY=value (or -KEY=value) set KEY, and a bare --KEY sets it to "true".
+	Any other arguments are ignored.
+	@params
+		args []string The command-line arguments, usually os.Args[1:]
+*/
+func WithCommandLineFlags(args []string) ConfigOpt {
+	return func(c *configGetter) *configGetter {
+		c.flag_data = parseCommandLineFlags(args)
+		return c
+	}
+}
+
+/*
+	GetConfigSource returns the layer that supplies the current value of variableName: CONFIG_SOURCE_FLAGS,
+	CONFIG_SOURCE_ENV, the path of a config file or the name of a config source. Returns an empty string if the config
+	var is not found.
+*/
+func (this *configGetter) GetConfigSource(variableName string) string {
+	_, source, _, _ := this.lookup(variableName)
+	return source
+}
+
+//configFilePaths returns the config files this getter reads, lowest precedence first
+func (this *configGetter) configFilePaths() []string {
+	config_files := []string{this.config_file_path + ".dist"}
+	if profile := os.Getenv(this.profile_env_var); this.profile_env_var != "" && profile != "" {
+		config_files = append(config_files, configLayerFilePath(this.config_file_path, profile))
+	}
+	return append(config_files, this.config_file_path, configLayerFilePath(this.config_file_path, "local"))
+}
+
+/*
+	configLayerFilePath inserts the layer name before the extension of config_file_path, so "config/config.json" with
+	the layer "production" becomes "config/config.production.json". A file with no name before its extension, like
+	".env", becomes ".env.production".
+*/
+func configLayerFilePath(config_file_path string, layer string) string {
+	dir, file := filepath.Split(config_file_path)
+	ext := filepath.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+	if base == "" {
+		return config_file_path + "." + layer
+	}
+	return dir + base + "." + layer + ext
+}
+
+//parseCommandLineFlags returns the --KEY=value arguments in args as a map. See WithCommandLineFlags.
+func parseCommandLineFlags(args []string) map[string]string {
+	flag_data := map[string]string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == "" {
+			continue
+		}
+		if separator := strings.Index(arg, "="); separator != -1 {
+			flag_data[arg[:separator]] = arg[separator+1:]
+		} else {
+			flag_data[arg] = "true"
+		}
+	}
+	return flag_data
+}
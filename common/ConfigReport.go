@@ -0,0 +1,55 @@
+package common
+
+import (
+	"sort"
+)
+
+//A ConfigEntry describes the effective value of one config var, see GetConfigReport
+type ConfigEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`          //The layer that supplied the value, see GetConfigSource
+	Secret bool   `json:"secret"`          //True if the value was resolved from a secret reference, see IsSecret
+	Error  string `json:"error,omitempty"` //Set if the value is a reference that could not be resolved
+}
+
+/*
+	GetConfigReport returns an entry for every known config var, sorted by key. Known config vars are the ones defined
+	in any config file or command-line flag, plus any that have been requested from this getter (which is how values
+	that are only set in environment variables show up). Config vars that aren't set anywhere are left out.
+	The values are not redacted, check Secret (and the key) before displaying them.
+*/
+func (this *configGetter) GetConfigReport() []ConfigEntry {
+	if err := this.loadConfigs(); err != nil {
+		Logger.Errorf("Config files could not be loaded, reporting the flags and environment only. Err: %v", err)
+	}
+
+	keys := map[string]bool{}
+	this.lock.RLock()
+	for key := range this.config_data {
+		keys[key] = true
+	}
+	this.lock.RUnlock()
+	for key := range this.flag_data {
+		keys[key] = true
+	}
+	this.requested_keys.Range(func(key interface{}, _ interface{}) bool {
+		keys[key.(string)] = true
+		return true
+	})
+
+	entries := []ConfigEntry{}
+	for key := range keys {
+		config, found, err := this.lookupResolved(key)
+		if !found {
+			continue
+		}
+		entry := ConfigEntry{Key: key, Value: config.value, Source: config.source, Secret: config.secret}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
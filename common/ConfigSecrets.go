@@ -0,0 +1,188 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+/*
+	When the getter is made with WithConfigReferences, config values (from files or environment variables) may be
+	references that are resolved when the value is first read, and then cached until the next Reload:
+		file:/run/secrets/jwt_private  The contents of the file, without trailing newlines
+		env:OTHER_VAR                  The value of the OTHER_VAR environment variable
+		base64:aGVsbG8=                The base64 decoded value
+	References are off by default, so a plain value like "env:production" is returned as it is. Encrypted values
+	(enc:v1:..., see ConfigCrypt.go) are always decrypted, since they can't be used as they are.
+	Values resolved from file:, base64: and enc:v1: references are flagged as secrets (see IsSecret) so they can be redacted.
+	A reference that can't be resolved is reported as a *ConfigError by the typed getters, MustGetConfigVar panics, and
+	SafeGetConfigVar returns an empty string.
+*/
+const CONFIG_REFERENCE_FILE = "file:"
+const CONFIG_REFERENCE_ENV = "env:"
+const CONFIG_REFERENCE_BASE64 = "base64:"
+
+//WithConfigReferences turns on the resolution of file:, env: and base64: references in config values
+func WithConfigReferences() ConfigOpt {
+	return func(c *configGetter) *configGetter {
+		c.references_enabled = true
+		return c
+	}
+}
+
+//The value shown in place of a secret
+const REDACTED_CONFIG_VALUE = "[REDACTED]"
+
+/*
+	Config vars whose (upper cased) keys match any of these patterns are sensitive, so their values are redacted in
+	ConfigError's like secrets are. See path.Match for the pattern syntax.
+*/
+var SENSITIVE_CONFIG_KEY_PATTERNS = []string{"*_SECRET", "*_KEY", "*PASSWORD*"}
+
+//IsSensitiveConfigKey returns true if key matches any of SENSITIVE_CONFIG_KEY_PATTERNS
+func IsSensitiveConfigKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, pattern := range SENSITIVE_CONFIG_KEY_PATTERNS {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+//A config value after any reference in it has been resolved
+type configValue struct {
+	value  string
+	source string //CONFIG_SOURCE_ENV, or the path of the config file the value was read from
+	secret bool   //True if the value was resolved from a secret reference
+}
+
+//displayValue is the value to use in errors and logs, with secrets redacted
+func (this configValue) displayValue() string {
+	if this.secret {
+		return REDACTED_CONFIG_VALUE
+	}
+	return this.value
+}
+
+//The cached result of resolving a reference
+type resolvedReference struct {
+	value  string
+	secret bool
+	err    error
+}
+
+/*
+	lookupResolved finds the value of highest precedence for variableName (see lookup), interpolates it (see
+	ConfigInterpolation.go) and resolves any reference in it
+	@returns
+		configValue The resolved value and where it came from
+		bool True if a value was found in any tier
+		error A *ConfigError if the value could not be interpolated or its reference could not be resolved, or the
+			error loading the config files (with found false), see loadConfigs
+*/
+func (this *configGetter) lookupResolved(variableName string) (configValue, bool, error) {
+	raw, source, found, err := this.lookup(variableName)
+	if err != nil || !found {
+		return configValue{}, false, err
+	}
+
+	config, err := this.resolveValue(raw, []string{variableName})
+	config.source = source
+	if err != nil {
+		display_raw := raw
+		if IsSensitiveConfigKey(variableName) {
+			display_raw = REDACTED_CONFIG_VALUE
+		} else if strings.HasPrefix(raw, CONFIG_REFERENCE_BASE64) {
+			display_raw = CONFIG_REFERENCE_BASE64 + REDACTED_CONFIG_VALUE
+		} else if strings.HasPrefix(raw, CONFIG_ENCRYPTED_PREFIX) {
+			display_raw = CONFIG_ENCRYPTED_PREFIX + REDACTED_CONFIG_VALUE
+		}
+		return config, true, &ConfigError{Key: variableName, Value: display_raw, Source: source, Err: err}
+	}
+	return config, true, nil
+}
+
+//resolveValue interpolates raw and then resolves it if it is a reference. See lookupResolved.
+func (this *configGetter) resolveValue(raw string, chain []string) (configValue, error) {
+	interpolated, secret, err := this.interpolate(raw, chain)
+	if err != nil {
+		return configValue{}, err
+	}
+
+	resolved := this.resolveReference(interpolated)
+	if resolved.err != nil {
+		return configValue{}, resolved.err
+	}
+	return configValue{value: resolved.value, secret: secret || resolved.secret}, nil
+}
+
+/*
+	IsSecret returns true if the value of variableName was resolved from a secret reference (file:, base64: or enc:v1:),
+	so that anything displaying config values knows to redact it.
+*/
+func (this *configGetter) IsSecret(variableName string) bool {
+	config, _, _ := this.lookupResolved(variableName)
+	return config.secret
+}
+
+//resolveReference resolves raw if it is a reference, caching the result
+func (this *configGetter) resolveReference(raw string) resolvedReference {
+	if !this.isConfigReference(raw) {
+		return resolvedReference{value: raw}
+	}
+
+	this.reference_lock.Lock()
+	defer this.reference_lock.Unlock()
+	if resolved, ok := this.references[raw]; ok {
+		return resolved
+	}
+	if this.references == nil {
+		this.references = map[string]resolvedReference{}
+	}
+
+	resolved := resolvedReference{}
+	switch {
+	case strings.HasPrefix(raw, CONFIG_REFERENCE_FILE):
+		contents, err := ioutil.ReadFile(strings.TrimPrefix(raw, CONFIG_REFERENCE_FILE))
+		resolved = resolvedReference{value: strings.TrimRight(string(contents), "\r\n"), secret: true, err: err}
+	case strings.HasPrefix(raw, CONFIG_REFERENCE_ENV):
+		env_name := strings.TrimPrefix(raw, CONFIG_REFERENCE_ENV)
+		value, ok := os.LookupEnv(env_name)
+		if !ok {
+			resolved.err = errors.New("environment variable " + env_name + " is not set")
+		}
+		resolved.value = value
+	case strings.HasPrefix(raw, CONFIG_REFERENCE_BASE64):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(raw, CONFIG_REFERENCE_BASE64))
+		if err != nil {
+			err = errors.New("invalid base64")
+		}
+		resolved = resolvedReference{value: string(decoded), secret: true, err: err}
+	case strings.HasPrefix(raw, CONFIG_ENCRYPTED_PREFIX):
+		decrypted, err := decryptConfigValue(raw)
+		resolved = resolvedReference{value: decrypted, secret: true, err: err}
+	}
+	this.references[raw] = resolved
+	return resolved
+}
+
+//clearResolvedReferences drops all cached references, so they are resolved again when next read
+func (this *configGetter) clearResolvedReferences() {
+	this.reference_lock.Lock()
+	defer this.reference_lock.Unlock()
+	this.references = nil
+}
+
+//isConfigReference returns true if raw is an encrypted value, or a reference and references are turned on
+func (this *configGetter) isConfigReference(raw string) bool {
+	if strings.HasPrefix(raw, CONFIG_ENCRYPTED_PREFIX) {
+		return true
+	}
+	return this.references_enabled && (strings.HasPrefix(raw, CONFIG_REFERENCE_FILE) ||
+		strings.HasPrefix(raw, CONFIG_REFERENCE_ENV) ||
+		strings.HasPrefix(raw, CONFIG_REFERENCE_BASE64))
+}
@@ -0,0 +1,230 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*
+	A ConfigSource supplies config values from somewhere other than the environment or config files, e.g. a config
+	service. Sources added with WithConfigSources are layered above all of the config files and below environment
+	variables, so the existing precedence model is kept: an environment variable still overrides a value from a source.
+	Later sources override earlier ones. Sources are loaded along with the config files, and again on each Reload, so a
+	watched getter (see Watch) polls its sources every interval.
+	A source that fails to load is logged and its previous values are kept (or it is skipped if it has never loaded),
+	rather than being fatal like a bad config file. The config files are still loaded and reloaded without it, and a
+	watched getter picks the source up once it can be loaded again.
+	A source can implement fmt.Stringer to name itself in GetConfigSource and GetConfigReport.
+*/
+type ConfigSource interface {
+	Load() (map[string]string, error)
+}
+
+//The source reported for values from a ConfigSource that doesn't implement fmt.Stringer
+const CONFIG_SOURCE_REMOTE = "remote"
+
+//WithConfigSources adds layers of config values from sources, see ConfigSource
+func WithConfigSources(sources ...ConfigSource) ConfigOpt {
+	return func(c *configGetter) *configGetter {
+		c.sources = append(c.sources, sources...)
+		return c
+	}
+}
+
+/*
+	readConfigLayers reads the config files and then loads the config sources. load_lock must be held.
+	@returns
+		map[string]string The merged config values
+		map[string]string The config file path or source name that supplied each value
+		error nil if all of the config files could be read and parsed
+*/
+func (this *configGetter) readConfigLayers() (map[string]string, map[string]string, error) {
+	config_data, config_sources, err := readConfigFiles(this.configFilePaths(), ConfigFileFormat(this.config_file_path))
+	if err != nil {
+		return nil, nil, err
+	}
+	this.loadConfigSources(config_data, config_sources)
+	return config_data, config_sources, nil
+}
+
+/*
+	loadConfigSources layers the values of each config source over config_data. A source that fails to load is logged
+	and its previous values are used, or it is skipped if it has never loaded. load_lock must be held.
+	@params
+		config_data map[string]string The config values to add to
+		config_sources map[string]string The config file path or source name that supplied each value
+*/
+func (this *configGetter) loadConfigSources(config_data map[string]string, config_sources map[string]string) {
+	if len(this.source_values) != len(this.sources) {
+		this.source_values = make([]map[string]string, len(this.sources))
+	}
+	for i, source := range this.sources {
+		source_data, err := source.Load()
+		if err != nil {
+			Logger.Errorf("Could not load config source %s, using its previous values. Err: %v", configSourceName(source), err)
+			source_data = this.source_values[i]
+		}
+		this.source_values[i] = source_data
+		for key, value := range source_data {
+			config_data[key] = value
+			config_sources[key] = configSourceName(source)
+		}
+	}
+}
+
+func configSourceName(source ConfigSource) string {
+	if stringer, ok := source.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return CONFIG_SOURCE_REMOTE
+}
+
+//Implements ConfigSource by fetching a JSON document over HTTP, see GetHTTPConfigSource
+type httpConfigSource struct {
+	url           string
+	snapshot_path string
+	client        *http.Client
+
+	lock   sync.Mutex
+	etag   string            //The ETag of the last good response
+	values map[string]string //The values of the last good response
+}
+
+//HTTPConfigSourceOpt's are wrapper functions that customize the source made by GetHTTPConfigSource
+type HTTPConfigSourceOpt func(*httpConfigSource) *httpConfigSource
+
+//WithHTTPClient sets the client used to fetch the config. The default client has a 10 second timeout.
+func WithHTTPClient(client *http.Client) HTTPConfigSourceOpt {
+	return func(s *httpConfigSource) *httpConfigSource {
+		s.client = client
+		return s
+	}
+}
+
+/*
+	GetHTTPConfigSource is the factory method for a ConfigSource that fetches a JSON config document (in the same
+	format as config.json) from url. The ETag of the last response is sent as If-None-Match, so polling an unchanged
+	document is cheap.
+	Each good response is saved to snapshot_path. If the endpoint can't be reached (or returns a bad response) the last
+	good values are used instead, from memory or, after a restart, from the snapshot. Load only fails if there are no
+	good values to fall back on.
+	@params
+		url string The endpoint to fetch the config from
+		snapshot_path string The file to cache the last good response in. Empty to not keep a snapshot.
+		options ...HTTPConfigSourceOpt Optional customizations
+*/
+func GetHTTPConfigSource(url string, snapshot_path string, options ...HTTPConfigSourceOpt) ConfigSource {
+	s := &httpConfigSource{
+		url:           url,
+		snapshot_path: snapshot_path,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range options {
+		s = opt(s)
+	}
+	return s
+}
+
+func (this *httpConfigSource) String() string {
+	return this.url
+}
+
+//Load fetches the config document, falling back to the last good values. See GetHTTPConfigSource.
+func (this *httpConfigSource) Load() (map[string]string, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	values, err := this.fetch()
+	if err == nil {
+		return values, nil
+	}
+
+	if this.values != nil {
+		Logger.Errorf("Could not fetch config from %s, using the last good values. Err: %v", this.url, err)
+		return this.values, nil
+	}
+	if snapshot, snapshot_err := this.readSnapshot(); snapshot_err == nil {
+		Logger.Errorf("Could not fetch config from %s, using the snapshot in %s. Err: %v", this.url, this.snapshot_path, err)
+		this.values = snapshot
+		return snapshot, nil
+	}
+	return nil, err
+}
+
+//fetch requests the config document, returning the previous values if it hasn't changed
+func (this *httpConfigSource) fetch() (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, this.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if this.etag != "" && this.values != nil {
+		req.Header.Set("If-None-Match", this.etag)
+	}
+
+	resp, err := this.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && this.values != nil {
+		return this.values, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseJsonConfig(body)
+	if err != nil {
+		return nil, err
+	}
+
+	this.etag = resp.Header.Get("ETag")
+	this.values = values
+	if err := this.writeSnapshot(body); err != nil {
+		Logger.Errorf("Could not save the config snapshot %s. Err: %v", this.snapshot_path, err)
+	}
+	return values, nil
+}
+
+func (this *httpConfigSource) readSnapshot() (map[string]string, error) {
+	if this.snapshot_path == "" {
+		return nil, errors.New("no snapshot")
+	}
+	contents, err := ioutil.ReadFile(this.snapshot_path)
+	if err != nil {
+		return nil, err
+	}
+	return parseJsonConfig(contents)
+}
+
+//writeSnapshot replaces the snapshot with contents, writing to a temporary file first so a crash can't truncate it
+func (this *httpConfigSource) writeSnapshot(contents []byte) error {
+	if this.snapshot_path == "" {
+		return nil
+	}
+	temp_file, err := ioutil.TempFile(filepath.Dir(this.snapshot_path), filepath.Base(this.snapshot_path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp_file.Name())
+	if _, err := temp_file.Write(contents); err != nil {
+		temp_file.Close()
+		return err
+	}
+	if err := temp_file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp_file.Name(), this.snapshot_path)
+}
@@ -0,0 +1,140 @@
+package common
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+//A snapshot of a config file used by Watch to detect changes
+type configFileFingerprint struct {
+	mod_time time.Time
+	size     int64
+	hash     [sha256.Size]byte
+}
+
+/*
+	Watch starts polling the config files (see ConfigLayers.go) for changes every interval. When any of them
+	changes they are reloaded (see Reload), and any callbacks registered with Subscribe are called for keys whose value
+	changed. A file that fails to parse is logged and the previous values are kept until it is fixed.
+	If the getter has config sources (see WithConfigSources) it is reloaded every interval, to poll them.
+	@params
+		interval time.Duration How often to check the files for changes
+	@returns
+		func() Stops the watcher
+*/
+func (this *configGetter) Watch(interval time.Duration) func() {
+	if err := this.loadConfigs(); err != nil { //So the first change is compared against the files as they are now
+		Logger.Errorf("Config could not be loaded, watching for it to be fixed. Err: %v", err)
+	}
+	config_files := this.configFilePaths()
+	fingerprints := make([]configFileFingerprint, len(config_files))
+	for i, config_file := range config_files {
+		fingerprints[i] = fingerprintConfigFile(config_file, configFileFingerprint{})
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				changed := false
+				for i, config_file := range config_files {
+					fingerprint := fingerprintConfigFile(config_file, fingerprints[i])
+					if fingerprint.hash != fingerprints[i].hash {
+						changed = true
+					}
+					fingerprints[i] = fingerprint
+				}
+				if changed || len(this.sources) > 0 {
+					if err := this.Reload(); err != nil {
+						Logger.Errorf("Config could not be reloaded, keeping the previous values. Err: %v", err)
+					}
+				}
+			}
+		}
+	}()
+
+	stop_once := sync.Once{}
+	return func() {
+		stop_once.Do(func() { close(stop) })
+	}
+}
+
+/*
+	Subscribe registers a callback that is called after a Reload changes the value of variableName. The values passed
+	are the ones that MustGetConfigVar would have returned before and after the reload, so a change to a file value
+	that is overridden by an environment variable does not trigger the callback.
+	@params
+		variableName string The config var to watch
+		callback func(old_value string, new_value string) Called with the previous and new values
+*/
+func (this *configGetter) Subscribe(variableName string, callback func(old_value string, new_value string)) {
+	this.subscription_lock.Lock()
+	defer this.subscription_lock.Unlock()
+	if this.subscriptions == nil {
+		this.subscriptions = map[string][]func(string, string){}
+	}
+	this.subscriptions[variableName] = append(this.subscriptions[variableName], callback)
+}
+
+//subscribedValues returns the current value of every subscribed config var
+func (this *configGetter) subscribedValues() map[string]string {
+	this.subscription_lock.Lock()
+	defer this.subscription_lock.Unlock()
+	values := map[string]string{}
+	for variableName := range this.subscriptions {
+		values[variableName] = this.SafeGetConfigVar(variableName)
+	}
+	return values
+}
+
+//notifySubscribers calls the callbacks of every subscribed config var whose value differs from old_values
+func (this *configGetter) notifySubscribers(old_values map[string]string) {
+	this.subscription_lock.Lock()
+	callbacks := map[string][]func(string, string){}
+	for variableName, subscribed := range this.subscriptions {
+		callbacks[variableName] = append([]func(string, string){}, subscribed...)
+	}
+	this.subscription_lock.Unlock()
+
+	for variableName, subscribed := range callbacks {
+		new_value := this.SafeGetConfigVar(variableName)
+		if new_value == old_values[variableName] {
+			continue
+		}
+		for _, callback := range subscribed {
+			callback(old_values[variableName], new_value)
+		}
+	}
+}
+
+/*
+	fingerprintConfigFile returns the fingerprint of config_file. The file is only hashed if its modification time or
+	size differ from the previous fingerprint. A missing file has an empty fingerprint.
+*/
+func fingerprintConfigFile(config_file string, previous configFileFingerprint) configFileFingerprint {
+	info, err := os.Stat(config_file)
+	if err != nil {
+		return configFileFingerprint{}
+	}
+	if info.ModTime().Equal(previous.mod_time) && info.Size() == previous.size {
+		return previous
+	}
+
+	contents, err := ioutil.ReadFile(config_file)
+	if err != nil {
+		return previous
+	}
+	return configFileFingerprint{
+		mod_time: info.ModTime(),
+		size:     info.Size(),
+		hash:     sha256.Sum256(contents),
+	}
+}
@@ -5,14 +5,20 @@ package common
 */
 type IResult interface {
 	GetChild() IResult
-	GetChildren() []chan asyncLogPackage
+	GetChildren() []*childReport
 	WasSuccessful() bool
 	Succeed()
-	Fail()
+	Fail(errs ...error)
 	Error() string
+	Unwrap() []error
+	Is(target error) bool
+	As(target interface{}) bool
 	MergeWithResult(r IResult)
 	GetMessages() []string
-	GetLogLevel() int
+	GetEntries() []LogEntry
+	GetFields() map[string]interface{}
+	With(key string, value interface{}) IResult
+	GetLogLevel() Level
 	GetStatusCode() int
 	SetStatusCode(int)
 	GetResponseMessage() string
@@ -21,5 +27,11 @@ type IResult interface {
 	Debugf(template string, args ...interface{})
 	DebugMessagef(template string, args ...interface{})
 	Infof(template string, args ...interface{})
+	Warnf(template string, args ...interface{})
 	Errorf(template string, args ...interface{})
+	Fatalf(template string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
 }
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//How long Flush waits for children (see GetChild) to be flushed when LOGGING_CHILD_TIMEOUT isn't set
+const DEFAULT_LOGGING_CHILD_TIMEOUT = 5 * time.Minute
+
+/*
+	A childReport connects a child made with GetChild to its parent. The child hands its output to the report when it
+	is flushed, without blocking, and the parent's Flush collects it. If the parent gives up on the child first (see
+	collectChildren) the report is abandoned, and the child writes its output to the sink itself when it is flushed.
+*/
+type childReport struct {
+	number int           //The child's number, as printed in the [CHILD #n STARTED] marker
+	done   chan struct{} //Closed when the child reports
+	result *commonResult //The parent, which errors recorded on the child are also recorded on. Guarded by lock.
+
+	lock      sync.Mutex
+	reported  bool
+	abandoned bool
+	output    asyncLogPackage
+}
+
+func newChildReport(number int, result *commonResult) *childReport {
+	return &childReport{
+		number: number,
+		done:   make(chan struct{}),
+		result: result,
+	}
+}
+
+//parentResult returns the result the child reports to
+func (this *childReport) parentResult() *commonResult {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.result
+}
+
+//adopt makes result the child's parent, when the child's parent is merged into result (see MergeWithResult)
+func (this *childReport) adopt(result *commonResult) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.result = result
+}
+
+//deliver hands the child's output to the parent. Returns false if the parent has already flushed without it.
+func (this *childReport) deliver(output asyncLogPackage) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.abandoned || this.reported {
+		return false
+	}
+	this.output = output
+	this.reported = true
+	close(this.done)
+	return true
+}
+
+//collect returns the child's output, or abandons the report if the child hasn't reported yet
+func (this *childReport) collect() (asyncLogPackage, bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if !this.reported {
+		this.abandoned = true
+		return asyncLogPackage{}, false
+	}
+	return this.output, true
+}
+
+/*
+	collectChildren waits for children to report, until they all have, the child timeout (see LOGGING_CHILD_TIMEOUT)
+	passes, or the result's context is cancelled. Children that report are added to the result's entries, and a single
+	summary line is logged for any that didn't.
+	@params
+		children []*childReport The reports of the children to wait for
+*/
+func (this *commonResult) collectChildren(children []*childReport) {
+	if len(children) == 0 {
+		return
+	}
+	timer := time.NewTimer(this.child_timeout)
+	defer timer.Stop()
+
+	reason := ""
+	missing := []string{}
+	for _, child := range children {
+		if child.parentResult() != this {
+			continue //The child was adopted by the result this one was merged into
+		}
+		if reason == "" {
+			select {
+			case <-child.done:
+			case <-timer.C:
+				reason = "after " + this.child_timeout.String()
+			case <-this.context().Done():
+				reason = "because the context ended: " + this.context().Err().Error()
+			}
+		}
+
+		child_output, ok := child.collect()
+		if !ok {
+			missing = append(missing, fmt.Sprintf("#%d", child.number))
+			continue
+		}
+		this.lock.Lock()
+		if !this.log_importance_level.AtLeast(child_output.log_importance_level) {
+			this.log_importance_level = child_output.log_importance_level
+		}
+		for _, entry := range child_output.entries {
+			entry.Child = child.number
+			this.entries = append(this.entries, entry)
+		}
+		this.lock.Unlock()
+	}
+
+	if len(missing) > 0 {
+		this.Errorf("[CHILDREN %s DID NOT REPORT] Flushed without them %s", strings.Join(missing, ", "), reason)
+	}
+}
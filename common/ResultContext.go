@@ -0,0 +1,42 @@
+package common
+
+import (
+	"context"
+)
+
+//The context key for the result stored by ContextWithResult
+type resultContextKey struct{}
+
+//ContextWithResult returns a copy of ctx that carries result, see ResultFromContext
+func ContextWithResult(ctx context.Context, result IResult) context.Context {
+	return context.WithValue(ctx, resultContextKey{}, result)
+}
+
+/*
+	ResultFromContext returns the result stored in ctx by ContextWithResult, e.g. the request's result made by
+	routing.ResultMiddleware. Returns nil if ctx doesn't carry a result.
+*/
+func ResultFromContext(ctx context.Context) IResult {
+	result, _ := ctx.Value(resultContextKey{}).(IResult)
+	return result
+}
+
+/*
+	MakeRequestResult is MakeCommonResultWithContext for the result of a request that is flushed by a middleware, e.g.
+	routing.ResultMiddleware, rather than by its handlers. Flush does nothing on the result, so a handler can't flush it
+	before the middleware has added its fields; the middleware flushes it with the returned func instead.
+	@returns
+		IResult The request's result
+		func() Flushes the result
+*/
+func MakeRequestResult(ctx context.Context, configs IConfigGetter, options ...ResultOpt) (IResult, func()) {
+	result := MakeCommonResultWithContext(ctx, configs, append(options, withFlushedByMiddleware())...).(*commonResult)
+	return result, result.flushNow
+}
+
+func withFlushedByMiddleware() ResultOpt {
+	return func(r *commonResult) *commonResult {
+		r.flushed_by_middleware = true
+		return r
+	}
+}
@@ -0,0 +1,163 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+//A LogEntry is one message logged to an IResult, along with its context and structured fields
+type LogEntry struct {
+	Level   string                 //The Level's name (e.g. "Warn"), or "Message". Empty for the child markers added by GetChild.
+	Message string                 //The formatted message
+	Time    time.Time              //When the message was logged. Zero for messages logged without context (DebugMessagef).
+	File    string                 //The file name of the caller that logged the message
+	Line    int                    //The line of the caller that logged the message
+	Fields  map[string]interface{} //Structured fields for this message, see IResult.Infow
+	Child   int                    //The number of the child (see GetChild) that logged the message, 0 for the result itself
+	TraceID string                 //The trace ID of the span in the result's context, if there is one
+	SpanID  string                 //The span ID of the span in the result's context, if there is one
+}
+
+/*
+	text formats the entry the way it has always been printed by Flush, with any fields added after the message:
+		[Warn] Bad response code  status_code=502 url=http://...  2017-11-02T13:58:18-06:00  APIRequest.go::169
+*/
+func (this LogEntry) text() string {
+	output := this.Message
+	if this.Level != "" {
+		output = "[" + this.Level + "] " + output
+	}
+	if len(this.Fields) > 0 {
+		output += "  " + formatFields(this.Fields)
+	}
+	if !this.Time.IsZero() {
+		output += fmt.Sprintf("  %s  %s::%d", this.Time.Format(time.RFC3339), this.File, this.Line)
+	}
+	return output + "\n"
+}
+
+//formatFields formats fields as space separated key=value pairs, sorted by key
+func formatFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, fields[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+/*
+	keyValueFields converts a list of alternating keys and values (as passed to Infow) into fields. A key that isn't a
+	string is formatted with %v, and a key without a value is kept with the value "(MISSING)".
+*/
+func keyValueFields(keysAndValues []interface{}) map[string]interface{} {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = "(MISSING)"
+		}
+	}
+	return fields
+}
+
+/*
+	withResultFields returns copies of entries with the result level fields added to each of them, so they aren't lost
+	when the entries are moved into another result. A field already set on an entry is kept.
+*/
+func withResultFields(entries []LogEntry, result_fields map[string]interface{}) []LogEntry {
+	copied := make([]LogEntry, len(entries))
+	for i, entry := range entries {
+		if len(result_fields) > 0 {
+			fields := make(map[string]interface{}, len(result_fields)+len(entry.Fields))
+			for key, value := range result_fields {
+				fields[key] = value
+			}
+			for key, value := range entry.Fields {
+				fields[key] = value
+			}
+			entry.Fields = fields
+		}
+		copied[i] = entry
+	}
+	return copied
+}
+
+//The JSON document printed by Flush when LOGGING_FORMAT is "json"
+type resultDocument struct {
+	Level      string                 `json:"level"` //The most important level that was logged, see GetLogLevel
+	Successful bool                   `json:"successful"`
+	StatusCode int                    `json:"status_code,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Entries    []entryDocument        `json:"entries"`
+}
+
+//An entry of a resultDocument
+type entryDocument struct {
+	Level     string                 `json:"level,omitempty"`
+	Message   string                 `json:"message"`
+	Timestamp string                 `json:"timestamp,omitempty"`
+	File      string                 `json:"file,omitempty"`
+	Line      int                    `json:"line,omitempty"`
+	Child     int                    `json:"child,omitempty"`
+	TraceID   string                 `json:"trace_id,omitempty"`
+	SpanID    string                 `json:"span_id,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+func makeEntryDocument(entry LogEntry) entryDocument {
+	document := entryDocument{
+		Level:   entry.Level,
+		Message: entry.Message,
+		File:    entry.File,
+		Line:    entry.Line,
+		Child:   entry.Child,
+		TraceID: entry.TraceID,
+		SpanID:  entry.SpanID,
+		Fields:  entry.Fields,
+	}
+	if !entry.Time.IsZero() {
+		document.Timestamp = entry.Time.Format(time.RFC3339Nano)
+	}
+	return document
+}
+
+//marshalResultDocument marshals document on one line. Fields that can't be marshaled are replaced with their %v format.
+func marshalResultDocument(document resultDocument) string {
+	output, err := json.Marshal(document)
+	if err != nil {
+		document.Fields = stringifyFields(document.Fields)
+		for i := range document.Entries {
+			document.Entries[i].Fields = stringifyFields(document.Entries[i].Fields)
+		}
+		output, _ = json.Marshal(document)
+	}
+	return string(output)
+}
+
+func stringifyFields(fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		return nil
+	}
+	stringified := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		stringified[key] = fmt.Sprintf("%v", value)
+	}
+	return stringified
+}
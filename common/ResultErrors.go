@@ -0,0 +1,103 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+	Unwrap returns the underlying errors recorded on the result, its merged results and its children, in the order they
+	were recorded. Errors are recorded by Fail, by Errorf and Fatalf when the template wraps them with %w, and by Errorw
+	when they are passed as field values. This makes a result work with errors.Is and errors.As:
+		if errors.Is(result, context.DeadlineExceeded) { ... }
+*/
+func (this *commonResult) Unwrap() []error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return append([]error{}, this.errs...)
+}
+
+/*
+	Is reports whether any of the result's errors (see Unwrap) matches target. errors.Is only follows Unwrap() []error
+	from Go 1.20 on, so the result matches its errors itself.
+*/
+func (this *commonResult) Is(target error) bool {
+	for _, err := range this.Unwrap() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+//As finds the first of the result's errors (see Unwrap) that matches target, and sets target to it. See Is.
+func (this *commonResult) As(target interface{}) bool {
+	for _, err := range this.Unwrap() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	recordErrors adds the non-nil errs to the result's errors (see Unwrap). A child's errors are recorded on its parent
+	straight away, so the parent doesn't have to be flushed to see them.
+*/
+func (this *commonResult) recordErrors(errs ...error) {
+	recorded := []error{}
+	for _, err := range errs {
+		if err != nil {
+			recorded = append(recorded, err)
+		}
+	}
+	if len(recorded) == 0 {
+		return
+	}
+
+	this.lock.Lock()
+	this.errs = append(this.errs, recorded...)
+	this.lock.Unlock()
+	if this.parent != nil {
+		this.parent.parentResult().recordErrors(recorded...)
+	}
+}
+
+/*
+	addErrorLog is a helper function for Errorf and Fatalf. The message is formatted with fmt.Errorf, so that an error
+	wrapped with %w is recorded (see Unwrap), and the message is recorded on the span when LOGGING_TRACE_ERRORS is true.
+	Errors are recorded even if the message is below the minimum level.
+*/
+func (this *commonResult) addErrorLog(level Level, template string, args []interface{}) {
+	err := fmt.Errorf(template, args...)
+	if wrapsErrors(err) {
+		this.recordErrors(err)
+	}
+	this.recordSpanError(err)
+	if !level.AtLeast(this.min_level) {
+		return
+	}
+	this.addEntry(3, level, level.String(), err.Error(), nil)
+}
+
+//wrapsErrors returns true if err was made by fmt.Errorf with at least one %w
+func wrapsErrors(err error) bool {
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		return wrapper.Unwrap() != nil
+	case interface{ Unwrap() []error }:
+		return len(wrapper.Unwrap()) > 0
+	}
+	return false
+}
+
+//errorValues returns the values in a list of alternating keys and values (as passed to Errorw) that are errors
+func errorValues(keysAndValues []interface{}) []error {
+	errs := []error{}
+	for i := 1; i < len(keysAndValues); i += 2 {
+		if err, ok := keysAndValues[i].(error); ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
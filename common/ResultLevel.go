@@ -0,0 +1,95 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+	A Level is how important a message logged to an IResult is. A result's level is the most important level logged to it.
+	Levels are numbered in order from LEVEL_DEBUG to LEVEL_FATAL, so they can be compared with < and >.
+*/
+type Level int
+
+const (
+	LEVEL_DEBUG Level = iota
+	LEVEL_INFO
+	LEVEL_WARN
+	LEVEL_ERROR
+	LEVEL_FATAL //Logged with Fatalf. Unlike the ZapLogger, Fatalf doesn't exit.
+)
+
+//Indexed by Level
+var level_names = []string{"Debug", "Info", "Warn", "Error", "Fatal"}
+
+//String returns the name printed for the level, e.g. "Warn"
+func (this Level) String() string {
+	if !this.valid() {
+		return "Level(" + strconv.Itoa(int(this)) + ")"
+	}
+	return level_names[this]
+}
+
+//AtLeast returns true if the level is as important as other or more, e.g. LEVEL_ERROR.AtLeast(LEVEL_WARN)
+func (this Level) AtLeast(other Level) bool {
+	return this >= other
+}
+
+func (this Level) valid() bool {
+	return this >= LEVEL_DEBUG && int(this) < len(level_names)
+}
+
+/*
+	ParseLevel parses a level name, as used in LOGGING_LEVEL and LOGGING_FLUSH_LEVEL. Names are case insensitive, and
+	"WARNING" is accepted for LEVEL_WARN.
+	@returns
+		Level The parsed level
+		bool False if name isn't a level
+*/
+func ParseLevel(name string) (Level, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "WARNING" {
+		return LEVEL_WARN, true
+	}
+	for i, level_name := range level_names {
+		if strings.ToUpper(level_name) == name {
+			return Level(i), true
+		}
+	}
+	return LEVEL_DEBUG, false
+}
+
+/*
+	loggingLevels reads LOGGING_LEVEL and LOGGING_FLUSH_LEVEL, see MakeCommonResult
+	@returns
+		Level The least important level that is recorded
+		Level The level a result has to reach to be printed when it is flushed
+		bool True if the logs should be beautified
+*/
+func loggingLevels(configs IConfigGetter) (Level, Level, bool) {
+	min_level := LEVEL_INFO
+	flush_level := LEVEL_DEBUG
+	beautify_logs := false
+
+	log_level := configs.SafeGetConfigVar("LOGGING_LEVEL")
+	switch log_level {
+	case "DEV":
+		min_level = LEVEL_DEBUG
+		beautify_logs = true
+	case "ERRORS_ONLY":
+		flush_level = LEVEL_ERROR
+	default:
+		if level, ok := ParseLevel(log_level); ok {
+			min_level = level
+		}
+	}
+
+	if flush_level_name := configs.SafeGetConfigVar("LOGGING_FLUSH_LEVEL"); flush_level_name != "" {
+		if level, ok := ParseLevel(flush_level_name); ok {
+			flush_level = level
+		} else {
+			Logger.Errorf("Invalid LOGGING_FLUSH_LEVEL '%s', printing every result", flush_level_name)
+		}
+	}
+	return min_level, flush_level, beautify_logs
+}
@@ -1,176 +1,351 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/api/trace"
 	"path"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-//Implements IResult
+/*
+	Implements IResult. A commonResult is safe for concurrent use, so children (see GetChild) can be handed to
+	goroutines, and the result can still be logged to while Flush is running in the background.
+*/
 type commonResult struct {
-	debug                bool                   //True if the result should log Debug level logs
-	beautify_logs        bool                   //True if the result should beautify the logs
-	errors_only          bool                   //True if the result should only print results that contain errors
-	messages             []string               // An array of accumulated logs with context
+	min_level             Level           //Messages below this level aren't recorded, see LOGGING_LEVEL
+	flush_level           Level           //The result is only printed if it reached this level, see LOGGING_FLUSH_LEVEL
+	beautify_logs         bool            //True if the result should beautify the logs
+	json_format           bool            //True if the result should be printed as a JSON document, see LOGGING_FORMAT
+	sink                  IResultSink     //Where the result is written when it is flushed, see ResultSinks.go
+	ctx                   context.Context //Flush stops waiting for children when this ends, see MakeCommonResultWithContext
+	child_timeout         time.Duration   //How long Flush waits for children, see LOGGING_CHILD_TIMEOUT
+	parent                *childReport    //If this is a child, this is how it reports to its parent
+	span                  trace.Span      //The span in ctx, if there is one, see useSpan
+	trace_errors          bool            //True if errors should be recorded on the span, see LOGGING_TRACE_ERRORS
+	flushed_by_middleware bool            //True if Flush does nothing, see MakeRequestResult
+
+	lock                 sync.Mutex             //Guards the fields below
+	entries              []LogEntry             //The accumulated logs with context
+	fields               map[string]interface{} //Structured fields that apply to every entry, see With
 	was_successful       bool                   //True if the result of the operation was successful
-	log_importance_level int                    //0-2, 0 if only debugs were written, 1 if Info was written, 2 if Error was written
+	log_importance_level Level                  //The most important level that was logged
 	status_code          int                    //The Status_code that was returned from an external request (if any)
 	response_message     string                 //The Error Message returned from an external request (if any)
-	parent               chan asyncLogPackage   //If this is a child, this will be the parent's channel
-	children             []chan asyncLogPackage //A slice of channels connected to any children that are created
+	children             []*childReport         //The reports of any children that are created
+	errs                 []error                //The underlying errors, see Unwrap
 }
 
 type asyncLogPackage struct {
-	messages             []string
-	log_importance_level int
+	entries              []LogEntry
+	log_importance_level Level
 }
 
+//The LOGGING_FORMAT that prints results as one JSON document per Flush. Any other value keeps the text format.
+const LOGGING_FORMAT_JSON = "json"
+
 /*
-	MakeCommonResult is the factory method for generating a commonResult for use.
+	MakeCommonResult is the factory method for generating a commonResult for use. The LOGGING_ config vars below are
+	read every time a result is made, so changes picked up by a watched IConfigGetter (see IConfigGetter.Watch) apply
+	without a restart.
+	LOGGING_LEVEL is the least important level that is recorded: DEBUG, INFO (the default), WARN, ERROR or FATAL. DEV
+	records everything and beautifies the logs, and ERRORS_ONLY is the same as LOGGING_FLUSH_LEVEL=ERROR.
+	LOGGING_FLUSH_LEVEL is the level a result has to reach to be printed when it is flushed, e.g. WARN to only print
+	results that had a warning or worse. By default every result is printed.
+	LOGGING_FORMAT=json prints each result as a JSON document, see formatJSON.
+	LOGGING_TRACE_ERRORS=true records Errorf, Fatalf and Errorw calls as error events on the span in the result's
+	context (see MakeCommonResultWithContext), and sets the span's status to an error.
+	LOGGING_CHILD_TIMEOUT is how long Flush waits for children (see GetChild) to be flushed, as a duration like "30s".
+	It defaults to DEFAULT_LOGGING_CHILD_TIMEOUT.
 	@params
 		configs IConfigGetter A configuration getter to retrieve the logging level
+		options ...ResultOpt Optional customizations, e.g. WithResultSink
 	@returns
 		IResult A pointer to a commonResult (which implements IResult)
 */
-func MakeCommonResult(configs IConfigGetter) IResult {
-	debug := false
-	beautify_logs := false
-	errors_only := false
-	log_level := configs.SafeGetConfigVar("LOGGING_LEVEL")
+func MakeCommonResult(configs IConfigGetter, options ...ResultOpt) IResult {
+	min_level, flush_level, beautify_logs := loggingLevels(configs)
+	result := &commonResult{
+		min_level:     min_level,
+		flush_level:   flush_level,
+		beautify_logs: beautify_logs,
+		json_format:   strings.ToLower(configs.SafeGetConfigVar("LOGGING_FORMAT")) == LOGGING_FORMAT_JSON,
+		sink:          DefaultResultSink,
+		child_timeout: loggingChildTimeout(configs),
+	}
+	result.trace_errors, _, _ = configs.LookupBool("LOGGING_TRACE_ERRORS")
+	for _, opt := range options {
+		result = opt(result)
+	}
+	result.useSpan()
+	return result
+}
 
-	if log_level == "DEBUG" {
-		debug = true
+/*
+	MakeCommonResultWithContext makes a result like MakeCommonResult, tied to ctx (usually the request's context). When
+	ctx ends, Flush stops waiting for children that haven't been flushed and flushes straight away. Children made with
+	GetChild share ctx.
+	If ctx carries an OpenTelemetry span, its trace and span IDs are added to the result's fields and to each entry, so
+	the output can be matched up with the trace.
+	@params
+		ctx context.Context The context the result is for
+		configs IConfigGetter A configuration getter to retrieve the logging level
+		options ...ResultOpt Optional customizations, e.g. WithResultSink
+*/
+func MakeCommonResultWithContext(ctx context.Context, configs IConfigGetter, options ...ResultOpt) IResult {
+	return MakeCommonResult(configs, append([]ResultOpt{withResultContext(ctx)}, options...)...)
+}
+
+func withResultContext(ctx context.Context) ResultOpt {
+	return func(r *commonResult) *commonResult {
+		r.ctx = ctx
+		return r
 	}
-	if log_level == "DEV" {
-		debug = true
-		beautify_logs = true
+}
+
+//loggingChildTimeout reads LOGGING_CHILD_TIMEOUT, see MakeCommonResult
+func loggingChildTimeout(configs IConfigGetter) time.Duration {
+	value := configs.SafeGetConfigVar("LOGGING_CHILD_TIMEOUT")
+	if value == "" {
+		return DEFAULT_LOGGING_CHILD_TIMEOUT
 	}
-	if log_level == "ERRORS_ONLY" {
-		errors_only = true
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		Logger.Errorf("Invalid LOGGING_CHILD_TIMEOUT '%s', using %s", value, DEFAULT_LOGGING_CHILD_TIMEOUT)
+		return DEFAULT_LOGGING_CHILD_TIMEOUT
 	}
-	return &commonResult{
-		debug:         debug,
-		beautify_logs: beautify_logs,
-		errors_only:   errors_only,
+	return timeout
+}
+
+//context returns the result's context, or the background context if it wasn't made with one
+func (this *commonResult) context() context.Context {
+	if this.ctx == nil {
+		return context.Background()
 	}
+	return this.ctx
 }
 
 /*
 	MakeDefaultCommonResult is used for testing purposes or to avoid having to set the logging level. Instead, it just
 	set's the logging level to debug.
 */
-func MakeDefaultCommonResult() IResult {
-	return &commonResult{
-		debug:         true,
+func MakeDefaultCommonResult(options ...ResultOpt) IResult {
+	result := &commonResult{
+		min_level:     LEVEL_DEBUG,
+		flush_level:   LEVEL_DEBUG,
 		beautify_logs: false,
+		sink:          DefaultResultSink,
+		child_timeout: DEFAULT_LOGGING_CHILD_TIMEOUT,
+	}
+	for _, opt := range options {
+		result = opt(result)
 	}
+	return result
 }
 
 func (this *commonResult) GetChild() IResult {
-	channel := make(chan asyncLogPackage)
+	this.lock.Lock()
+	child_number := len(this.children) + 1
+	report := newChildReport(child_number, this)
 	child := &commonResult{
-		debug:         this.debug,
+		min_level:     this.min_level,
+		flush_level:   this.flush_level,
 		beautify_logs: this.beautify_logs,
-		parent:        channel,
+		json_format:   this.json_format,
+		sink:          this.sink,
+		ctx:           this.ctx,
+		child_timeout: this.child_timeout,
+		span:          this.span,
+		trace_errors:  this.trace_errors,
+		fields:        copyFields(this.fields),
+		parent:        report,
 	}
-	this.children = append(this.children, channel)
+	this.children = append(this.children, report)
+	this.lock.Unlock()
 
-	parent_log := fmt.Sprintf("[CHILD #%s STARTED]", strconv.Itoa(len(this.children)))
-	this.addLog(parent_log, "")
-	child_log := fmt.Sprintf("[CHILD #%s OUTPUT]", strconv.Itoa(len(this.children)))
-	child.addLog(child_log, "")
+	parent_log := fmt.Sprintf("[CHILD #%s STARTED]", strconv.Itoa(child_number))
+	this.addEntry(2, LEVEL_DEBUG, "", parent_log, nil)
+	child_log := fmt.Sprintf("[CHILD #%s OUTPUT]", strconv.Itoa(child_number))
+	child.addEntry(2, LEVEL_DEBUG, "", child_log, nil)
 
 	return child
 }
 
 //WasSuccessful Returns a boolean that's true if everything went well, false if there was an error
 func (this *commonResult) WasSuccessful() bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	return this.was_successful
 }
 
-//Succeed set's the results was_successful flag to true
+/*
+	Succeed set's the results was_successful flag to true. Errors recorded before it (see Unwrap), e.g. by the failed
+	tries of a request that was retried, are cleared, so a successful result doesn't match them with errors.Is.
+*/
 func (this *commonResult) Succeed() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	this.was_successful = true
+	this.errs = nil
 }
 
-//Fail set's the results was_successful flag to false
-func (this *commonResult) Fail() {
+//Fail set's the results was_successful flag to false, and records errs as the reason (see Unwrap)
+func (this *commonResult) Fail(errs ...error) {
+	this.recordErrors(errs...)
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	this.was_successful = false
 }
 
 //Implements error interface
 func (this *commonResult) Error() string {
-	if this.messages == nil || len(this.messages) == 0 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if len(this.entries) == 0 {
 		return ""
 	}
-	return this.messages[len(this.messages)-1]
+	return this.entries[len(this.entries)-1].text()
 }
 
 /*
 	MergeWithResult is a function for merging a result that was returned from a function call with the caller's
-	result to be returned to the level above.
+	result to be returned to the level above. The merged entries keep r's fields (see With), and r's errors are added
+	to this result's (see Unwrap). r's children now report to this result, and r's Flush no longer waits for them.
 	@params
 		r IResult The result from the function below to merge with this one
 */
 func (this *commonResult) MergeWithResult(r IResult) {
-	if r == nil {
+	if r == nil || r == IResult(this) {
 		return
 	}
-	for _, v := range r.GetMessages() {
-		this.messages = append(this.messages, v)
+	//Read everything from r before locking this, so two results merging each other can't deadlock
+	entries := withResultFields(r.GetEntries(), r.GetFields())
+	log_level := r.GetLogLevel()
+	children := r.GetChildren()
+	response_message := r.GetResponseMessage()
+	status_code := r.GetStatusCode()
+	errs := r.Unwrap()
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.entries = append(this.entries, entries...)
+	if !this.log_importance_level.AtLeast(log_level) {
+		this.log_importance_level = log_level
 	}
-
-	if this.log_importance_level < r.GetLogLevel() {
-		this.log_importance_level = r.GetLogLevel()
+	for _, child := range children {
+		child.adopt(this)
 	}
-
-	this.children = append(this.children, r.GetChildren()...)
-	this.response_message = r.GetResponseMessage()
-	this.status_code = r.GetStatusCode()
+	this.children = append(this.children, children...)
+	this.errs = append(this.errs, errs...)
+	this.response_message = response_message
+	this.status_code = status_code
 }
 
-func (this *commonResult) GetChildren() []chan asyncLogPackage {
-	return this.children
+func (this *commonResult) GetChildren() []*childReport {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return append([]*childReport{}, this.children...)
 }
 
 //GetMessages Returns the []string messages in this result
 func (this *commonResult) GetMessages() []string {
-	return this.messages
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.messages()
+}
+
+//messages formats the entries as strings. The lock must be held.
+func (this *commonResult) messages() []string {
+	messages := make([]string, len(this.entries))
+	for i, entry := range this.entries {
+		messages[i] = entry.text()
+	}
+	return messages
 }
 
-//GetMessages Returns the current logging level. This goes up if Infof or Errorf are called.
-func (this *commonResult) GetLogLevel() int {
+//GetEntries Returns the structured log entries in this result
+func (this *commonResult) GetEntries() []LogEntry {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return append([]LogEntry{}, this.entries...)
+}
+
+//GetFields Returns a copy of the fields set on this result with With
+func (this *commonResult) GetFields() map[string]interface{} {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return copyFields(this.fields)
+}
+
+/*
+	With sets a structured field (e.g. "url" or "status_code") on the result. It is attached to every entry when the
+	result is flushed or merged into another result, and is inherited by children made with GetChild afterwards.
+	@params
+		key string The name of the field
+		value interface{} The value of the field
+	@returns
+		IResult This result, so calls can be chained
+*/
+func (this *commonResult) With(key string, value interface{}) IResult {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.fields == nil {
+		this.fields = map[string]interface{}{}
+	}
+	this.fields[key] = value
+	return this
+}
+
+//GetLogLevel Returns the most important level that was logged, e.g. LEVEL_WARN after Warnf is called
+func (this *commonResult) GetLogLevel() Level {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	return this.log_importance_level
 }
 
 //Get's status code
 func (this *commonResult) GetStatusCode() int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	return this.status_code
 }
 
 //Set's status code
 func (this *commonResult) SetStatusCode(code int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	this.status_code = code
 }
 
 //Get the reponse error to show to the user
 func (this *commonResult) GetResponseMessage() string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	return this.response_message
 }
 
 //Set the reponse error to show to the user
 func (this *commonResult) SetResponseMessage(msg string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	this.response_message = msg
 }
 
 /*
 	Flush is meant to be a deferred function call at the top-most level of the request. When called, it formats the
 	messages that it has gathered, gets all of the messages from any children that it has, and outputs them
-	either through the base fmt package so as to avoid unintentional styling, or if the result has a parent it will
-	write to them.
+	to the result's sink (see ResultSinks.go, by default through the base fmt package so as to avoid unintentional
+	styling), or if the result has a parent it will write to them. The result's fields (see With) are printed on a
+	"[Fields]" line before the messages. Messages logged while Flush is running are kept for the next Flush.
+	Flush doesn't block: it waits for children in the background, for up to LOGGING_CHILD_TIMEOUT or until the result's
+	context ends (see MakeCommonResultWithContext), and logs a summary line for any children that didn't report. A
+	child flushed after its parent gave up on it writes to the sink itself. A child without children of its own has
+	reported to its parent by the time Flush returns.
 
 	Sample output:
 
@@ -181,69 +356,140 @@ func (this *commonResult) SetResponseMessage(msg string) {
 	1[Info] Message: Hello There. I'm AnotherFunction
  	Timestamp: 2017-11-02 13:58:18.5401363 -0600 MDT
  	Caller: C:/Users/brandon.echols/Documents/Coding/Go/src/playground/old stuff/CommonResult.go::31
+
+	Flush does nothing on a result made with MakeRequestResult, which its middleware flushes instead.
 */
 func (this *commonResult) Flush() {
-	go func() { //In case we have to wait for children or parents, let the calling function exit
-		my_logs_length := len(this.messages)
-
-		//For each child, get their output and append it to our own
-		for i, child := range this.children {
-			//Blocks until Flush is called on this child, or 5 minutes has passed
-			select {
-			case child_output := <-child:
-				if this.log_importance_level < child_output.log_importance_level {
-					this.log_importance_level = child_output.log_importance_level
-				}
-				this.messages = append(this.messages, child_output.messages...)
-			case <-time.After(time.Minute * 5):
-				this.Errorf("CHILD %d DID NOT COME HOME!! We're flushing without them", i+1)
-			}
-		}
+	if this.flushed_by_middleware {
+		return
+	}
+	this.flushNow()
+}
 
-		output := ""
-		if this.beautify_logs {
-			for i, msg := range this.messages {
-				if i < my_logs_length {
-					num := strconv.Itoa(i)
-					this.messages[i] = num + " " + msg
-				} else {
-					this.messages[i] = "-" + msg
-				}
-				output += this.messages[i]
-			}
-		} else {
-			for i, msg := range this.messages {
-				if i < my_logs_length {
-					num := strconv.Itoa(i)
-					this.messages[i] = num + ") " + msg
-				} else {
-					this.messages[i] = "-" + msg
-				}
-				output += this.messages[i]
-			}
-			output = strings.Replace(output, "\n", "  :|: ", -1)
+//flushNow is Flush, for results that are flushed by a middleware too, see MakeRequestResult
+func (this *commonResult) flushNow() {
+	this.lock.Lock()
+	children := this.children
+	this.children = nil
+	this.lock.Unlock()
+
+	if this.parent != nil && len(children) == 0 {
+		//Reporting to a parent doesn't block, so a child without children of its own reports straight away
+		this.flush(children)
+		return
+	}
+	go this.flush(children) //In case we have to wait for children, let the calling function exit
+}
+
+//flush does the work of Flush, after waiting for children
+func (this *commonResult) flush(children []*childReport) {
+	this.collectChildren(children)
+
+	if this.parent != nil { //We are not the top, so we'll pass on our stuff
+		this.lock.Lock()
+		log_pack := asyncLogPackage{
+			entries:              withResultFields(this.entries, this.fields),
+			log_importance_level: this.log_importance_level,
+		}
+		this.entries = []LogEntry{}
+		this.lock.Unlock()
+		if this.parent.deliver(log_pack) {
+			return
 		}
+		//The parent was flushed without us, so we'll print our own output
+		this.lock.Lock()
+		this.entries = append(log_pack.entries, this.entries...)
+		this.lock.Unlock()
+		this.Errorf("[CHILD #%d REPORTED AFTER ITS PARENT WAS FLUSHED]", this.parent.number)
+	}
+
+	this.lock.Lock()
+	output := ""
+	if this.json_format {
+		output = this.formatJSON()
+	} else {
+		output = this.formatText()
+	}
+	flushed := this.flushedResult(output)
+	should_print := this.log_importance_level.AtLeast(this.flush_level)
+	this.entries = []LogEntry{}
+	this.lock.Unlock()
+
+	if should_print {
+		this.write(flushed)
+	}
+}
+
+//flushedResult snapshots the result for its sink. The lock must be held.
+func (this *commonResult) flushedResult(output string) FlushedResult {
+	return FlushedResult{
+		Output:  output,
+		Level:   this.log_importance_level,
+		Entries: append([]LogEntry{}, this.entries...),
+		Fields:  copyFields(this.fields),
+	}
+}
+
+//write hands a flushed result to the result's sink
+func (this *commonResult) write(flushed FlushedResult) {
+	sink := this.sink
+	if sink == nil {
+		sink = StdoutResultSink{}
+	}
+	sink.Write(flushed)
+}
 
-		if this.parent != nil { //We are not the top, so we'll pass on our stuff
-			log_pack := asyncLogPackage{
-				messages:             this.messages,
-				log_importance_level: this.log_importance_level,
-			}
-			//Blocks until Flush is called on parent, or 5 minutes has passed
-			select {
-			case this.parent <- log_pack: //Send all of our output to the parent
-			case <-time.After(time.Minute * 5):
-				this.Errorf("PARENT NOT LISTENING!!! We'll move on without them")
-				fmt.Println(output)
-			}
-		} else { //We're the top so we'll print
-			if !(this.log_importance_level < 2 && this.errors_only) {
-				fmt.Println(output)
-			}
+/*
+	formatText formats the result's messages for Flush. The result's own messages are numbered and its children's are
+	prefixed with a "-". The lock must be held.
+*/
+func (this *commonResult) formatText() string {
+	output := ""
+	if len(this.fields) > 0 && this.parent == nil {
+		output = "[Fields] " + formatFields(this.fields) + "\n"
+	}
+	separator := ") "
+	if this.beautify_logs {
+		separator = " "
+	}
+
+	own_messages := 0
+	for i, msg := range this.messages() {
+		if this.entries[i].Child == 0 {
+			output += strconv.Itoa(own_messages) + separator + msg
+			own_messages++
+		} else {
+			output += "-" + msg
 		}
+	}
+	if this.beautify_logs {
+		return output
+	}
+	return strings.Replace(output, "\n", "  :|: ", -1)
+}
 
-		this.messages = []string{}
-	}()
+/*
+	formatJSON formats the result for Flush as a single line JSON document, for log aggregators:
+	{"level":"Error","successful":false,"status_code":502,"fields":{"request_id":"abc"},"entries":[
+		{"level":"Info","message":"Calling dependency","timestamp":"2017-11-02T13:58:18.5391358-06:00",
+			"file":"handler.go","line":18},
+		{"level":"Error","message":"Bad response","timestamp":"...","file":"APIRequest.go","line":169,"child":1,
+			"fields":{"status_code":502,"url":"http://..."}}
+	]}
+	The lock must be held.
+*/
+func (this *commonResult) formatJSON() string {
+	document := resultDocument{
+		Level:      this.log_importance_level.String(),
+		Successful: this.was_successful,
+		StatusCode: this.status_code,
+		Fields:     this.fields,
+		Entries:    make([]entryDocument, len(this.entries)),
+	}
+	for i, entry := range this.entries {
+		document.Entries[i] = makeEntryDocument(entry)
+	}
+	return marshalResultDocument(document)
 }
 
 /*
@@ -254,50 +500,96 @@ func (this *commonResult) Flush() {
 		args ...interface{} A list of arguments to inject into the template
 */
 func (this *commonResult) Debugf(template string, args ...interface{}) {
-	if !this.debug {
-		return
-	}
-
-	original_message := fmt.Sprintf(template, args...)
-	this.addLog("[Debug]", original_message)
+	this.addLog(LEVEL_DEBUG, template, args)
 }
 
 func (this *commonResult) Infof(template string, args ...interface{}) {
-	if this.log_importance_level < 1 {
-		this.log_importance_level = 1
-	}
-	original_message := fmt.Sprintf(template, args...)
-	this.addLog("[Info]", original_message)
+	this.addLog(LEVEL_INFO, template, args)
+}
+
+func (this *commonResult) Warnf(template string, args ...interface{}) {
+	this.addLog(LEVEL_WARN, template, args)
 }
 
 func (this *commonResult) Errorf(template string, args ...interface{}) {
-	if this.log_importance_level < 2 {
-		this.log_importance_level = 2
-	}
+	this.addErrorLog(LEVEL_ERROR, template, args)
+}
 
-	original_message := fmt.Sprintf(template, args...)
-	this.addLog("[Error]", original_message)
+//Fatalf records a LEVEL_FATAL message. Unlike the ZapLogger's Fatalf it doesn't exit, so the result can still be flushed.
+func (this *commonResult) Fatalf(template string, args ...interface{}) {
+	this.addErrorLog(LEVEL_FATAL, template, args)
 }
 
-//addLog is a helper function for the *f methods.
-func (this *commonResult) addLog(header string, org_msg string) {
-	_, file, line, _ := runtime.Caller(2)
-	_, fileName := path.Split(file)
+/*
+	The following methods are like the *f methods above, but take a constant message and structured fields, like the
+	ZapLogger's *w methods.
+	@params
+		msg string The message
+		keysAndValues ...interface{} Alternating field names and values, e.g. "url", url, "status_code", 502
+*/
+func (this *commonResult) Debugw(msg string, keysAndValues ...interface{}) {
+	this.addEntry(2, LEVEL_DEBUG, LEVEL_DEBUG.String(), msg, keyValueFields(keysAndValues))
+}
+
+func (this *commonResult) Infow(msg string, keysAndValues ...interface{}) {
+	this.addEntry(2, LEVEL_INFO, LEVEL_INFO.String(), msg, keyValueFields(keysAndValues))
+}
 
-	org_msg = strings.TrimSuffix(org_msg, "\n")
+func (this *commonResult) Warnw(msg string, keysAndValues ...interface{}) {
+	this.addEntry(2, LEVEL_WARN, LEVEL_WARN.String(), msg, keyValueFields(keysAndValues))
+}
+
+//Errorw records any field values that are errors, see Unwrap
+func (this *commonResult) Errorw(msg string, keysAndValues ...interface{}) {
+	errs := errorValues(keysAndValues)
+	this.recordErrors(errs...)
+	if len(errs) == 0 {
+		errs = append(errs, errors.New(msg))
+	}
+	for _, err := range errs {
+		this.recordSpanError(err)
+	}
+	this.addEntry(2, LEVEL_ERROR, LEVEL_ERROR.String(), msg, keyValueFields(keysAndValues))
+}
+
+//addLog is a helper function for the *f methods
+func (this *commonResult) addLog(level Level, template string, args []interface{}) {
+	if !level.AtLeast(this.min_level) {
+		return
+	}
+	this.addEntry(3, level, level.String(), fmt.Sprintf(template, args...), nil)
+}
 
-	output := fmt.Sprintf(
-		header+" %s  %s  %s::%d",
-		org_msg,
-		time.Now().Format(time.RFC3339),
-		fileName,
-		line,
-	)
-	if !strings.HasSuffix(output, "\n") {
-		output += "\n"
+/*
+	addEntry records a message, unless its level is below the result's minimum level (see LOGGING_LEVEL). It raises
+	the result's level to level if it is lower. Entries without a label (the child markers) are always recorded.
+	@params
+		caller_depth int How far up the stack the caller that logged the message is, for runtime.Caller
+		level Level The level of the message
+		label string The level printed with the message, empty for none
+		org_msg string The message
+		fields map[string]interface{} Structured fields for the message
+*/
+func (this *commonResult) addEntry(caller_depth int, level Level, label string, org_msg string, fields map[string]interface{}) {
+	if label != "" && !level.AtLeast(this.min_level) {
+		return
 	}
+	_, file, line, _ := runtime.Caller(caller_depth)
+	_, fileName := path.Split(file)
 
-	this.messages = append(this.messages, output)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if !this.log_importance_level.AtLeast(level) {
+		this.log_importance_level = level
+	}
+	this.entries = append(this.entries, this.stampSpan(LogEntry{
+		Level:   label,
+		Message: strings.TrimSuffix(org_msg, "\n"),
+		Time:    time.Now(),
+		File:    fileName,
+		Line:    line,
+		Fields:  fields,
+	}))
 }
 
 /*
@@ -307,17 +599,27 @@ func (this *commonResult) addLog(header string, org_msg string) {
 		args ...interface{} A list of arguments to inject into the template
 */
 func (this *commonResult) DebugMessagef(template string, args ...interface{}) {
-	if !this.debug {
+	if !LEVEL_DEBUG.AtLeast(this.min_level) {
 		return
 	}
 
-	if !strings.HasSuffix(template, "\n") {
-		template += "\n"
-	}
-
 	original_message := fmt.Sprintf(template, args...)
-	this.messages = append(this.messages, fmt.Sprintf(
-		"[Message] %s",
-		original_message,
-	))
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.entries = append(this.entries, this.stampSpan(LogEntry{
+		Level:   "Message",
+		Message: strings.TrimSuffix(original_message, "\n"),
+	}))
+}
+
+//copyFields returns a copy of fields, or nil if there are none
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+	return copied
 }
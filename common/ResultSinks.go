@@ -0,0 +1,128 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+	An IResultSink is where a result's output goes when it is flushed (see IResult.Flush). A result uses the sink it
+	was made with (see WithResultSink), or DefaultResultSink at the time it was made. Children use their parent's sink.
+*/
+type IResultSink interface {
+	Write(flushed FlushedResult)
+}
+
+//A FlushedResult is what a result writes to its sink when it is flushed
+type FlushedResult struct {
+	Output  string                 //The formatted output, as text or a JSON document depending on LOGGING_FORMAT
+	Level   Level                  //The result's level when it was flushed, see IResult.GetLogLevel
+	Entries []LogEntry             //The result's entries, including its children's
+	Fields  map[string]interface{} //The result's fields, see IResult.With
+}
+
+//The sink used by results that aren't made with WithResultSink. Replace it to route all results, e.g. to the Logger.
+var DefaultResultSink IResultSink = StdoutResultSink{}
+
+//ResultOpt's are wrapper functions that customize a result when it is made with MakeCommonResult
+type ResultOpt func(*commonResult) *commonResult
+
+//WithResultSink makes the result (and its children) write to sink when flushed
+func WithResultSink(sink IResultSink) ResultOpt {
+	return func(r *commonResult) *commonResult {
+		r.sink = sink
+		return r
+	}
+}
+
+//StdoutResultSink prints results through the base fmt package so as to avoid unintentional styling. This is the default.
+type StdoutResultSink struct{}
+
+func (this StdoutResultSink) Write(flushed FlushedResult) {
+	fmt.Println(flushed.Output)
+}
+
+//Implements IResultSink by writing each result on its own line to an io.Writer, see GetWriterResultSink
+type writerResultSink struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+//GetWriterResultSink returns a sink that writes results to writer, e.g. a log file. Writes are serialized.
+func GetWriterResultSink(writer io.Writer) IResultSink {
+	return &writerResultSink{writer: writer}
+}
+
+func (this *writerResultSink) Write(flushed FlushedResult) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	fmt.Fprintln(this.writer, flushed.Output)
+}
+
+/*
+	LoggerResultSink writes results to the package Logger (the zap logger once InitializeLogger has been called), at
+	the result's level. LEVEL_FATAL results are logged as errors, so the Logger doesn't exit.
+*/
+type LoggerResultSink struct{}
+
+func (this LoggerResultSink) Write(flushed FlushedResult) {
+	switch {
+	case flushed.Level.AtLeast(LEVEL_ERROR):
+		Logger.Error(flushed.Output)
+	case flushed.Level == LEVEL_WARN:
+		Logger.Warn(flushed.Output)
+	case flushed.Level == LEVEL_INFO:
+		Logger.Info(flushed.Output)
+	default:
+		Logger.Debug(flushed.Output)
+	}
+}
+
+//MemoryResultSink keeps flushed results in memory, so tests can make assertions about them. See GetMemoryResultSink.
+type MemoryResultSink struct {
+	lock    sync.Mutex
+	results []FlushedResult
+}
+
+//GetMemoryResultSink returns an empty MemoryResultSink
+func GetMemoryResultSink() *MemoryResultSink {
+	return &MemoryResultSink{}
+}
+
+func (this *MemoryResultSink) Write(flushed FlushedResult) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.results = append(this.results, flushed)
+}
+
+//GetResults returns the results that have been written so far
+func (this *MemoryResultSink) GetResults() []FlushedResult {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return append([]FlushedResult{}, this.results...)
+}
+
+/*
+	WaitForResults waits until at least count results have been written, since Flush writes in the background
+	@params
+		count int The number of results to wait for
+		timeout time.Duration How long to wait
+	@returns
+		[]FlushedResult The results written so far
+		bool False if the timeout passed first
+*/
+func (this *MemoryResultSink) WaitForResults(count int, timeout time.Duration) ([]FlushedResult, bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		results := this.GetResults()
+		if len(results) >= count {
+			return results, true
+		}
+		if time.Now().After(deadline) {
+			return results, false
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+)
+
+//The fields set on a result made from a context carrying a span, see MakeCommonResultWithContext
+const (
+	RESULT_FIELD_TRACE_ID = "trace_id"
+	RESULT_FIELD_SPAN_ID  = "span_id"
+)
+
+/*
+	useSpan ties the result to the OpenTelemetry span in its context (as started by routing's OpenTelemetryMiddleware
+	or JaegerHTTPClientWrapper), if there is one. The span's trace and span IDs are set as fields, so they are in the
+	flushed output, and are added to each entry (see LogEntry).
+*/
+func (this *commonResult) useSpan() {
+	span := trace.SpanFromContext(this.context())
+	span_context := span.SpanContext()
+	if !span_context.IsValid() {
+		return
+	}
+	this.span = span
+	if this.fields == nil {
+		this.fields = map[string]interface{}{}
+	}
+	this.fields[RESULT_FIELD_TRACE_ID] = span_context.TraceID.String()
+	this.fields[RESULT_FIELD_SPAN_ID] = span_context.SpanID.String()
+}
+
+//stampSpan adds the trace and span IDs of the result's span (if it has one) to entry
+func (this *commonResult) stampSpan(entry LogEntry) LogEntry {
+	if this.span != nil {
+		span_context := this.span.SpanContext()
+		entry.TraceID = span_context.TraceID.String()
+		entry.SpanID = span_context.SpanID.String()
+	}
+	return entry
+}
+
+/*
+	recordSpanError records err as an error event on the result's span, and sets the span's status to an error, when
+	LOGGING_TRACE_ERRORS is true. See MakeCommonResult.
+*/
+func (this *commonResult) recordSpanError(err error) {
+	if !this.trace_errors || this.span == nil || err == nil {
+		return
+	}
+	this.span.RecordError(this.context(), err)
+	this.span.SetStatus(codes.Unknown, err.Error())
+}
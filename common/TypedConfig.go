@@ -0,0 +1,196 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//The source reported for values that were read from an environment variable
+const CONFIG_SOURCE_ENV = "env"
+
+var CONFIG_VAR_NOT_FOUND_ERR error = errors.New("not found")
+
+/*
+	ConfigError is returned by the typed getters when a config value is missing or can not be parsed.
+	Source is the tier that supplied the bad value: CONFIG_SOURCE_ENV, or the path of the config file it came from.
+*/
+type ConfigError struct {
+	Key    string
+	Value  string
+	Source string
+	Err    error
+}
+
+func (this *ConfigError) Error() string {
+	if this.Source == "" {
+		return fmt.Sprintf("config var %s: %s", this.Key, this.Err.Error())
+	}
+	return fmt.Sprintf("config var %s from %s: invalid value %q: %s", this.Key, this.Source, this.Value, this.Err.Error())
+}
+
+func (this *ConfigError) Unwrap() error {
+	return this.Err
+}
+
+/*
+	The following Get* methods load a config value with the same precedence as MustGetConfigVar and parse it into the
+	named type. Instead of panicking they return a *ConfigError if the value is missing or can not be parsed.
+	@params
+		variableName string The string value in the json file or environment variable name of the config to load
+*/
+
+func (this *configGetter) GetString(variableName string) (string, error) {
+	value, found, err := this.LookupString(variableName)
+	if err == nil && !found {
+		err = notFoundError(variableName)
+	}
+	return value, err
+}
+
+func (this *configGetter) GetInt(variableName string) (int, error) {
+	value, found, err := this.LookupInt(variableName)
+	if err == nil && !found {
+		err = notFoundError(variableName)
+	}
+	return value, err
+}
+
+func (this *configGetter) GetBool(variableName string) (bool, error) {
+	value, found, err := this.LookupBool(variableName)
+	if err == nil && !found {
+		err = notFoundError(variableName)
+	}
+	return value, err
+}
+
+func (this *configGetter) GetFloat(variableName string) (float64, error) {
+	value, found, err := this.LookupFloat(variableName)
+	if err == nil && !found {
+		err = notFoundError(variableName)
+	}
+	return value, err
+}
+
+func (this *configGetter) GetDuration(variableName string) (time.Duration, error) {
+	value, found, err := this.LookupDuration(variableName)
+	if err == nil && !found {
+		err = notFoundError(variableName)
+	}
+	return value, err
+}
+
+func (this *configGetter) GetStringSlice(variableName string) ([]string, error) {
+	value, found, err := this.LookupStringSlice(variableName)
+	if err == nil && !found {
+		err = notFoundError(variableName)
+	}
+	return value, err
+}
+
+/*
+	The following Lookup* methods are the same as the Get* methods, but a missing value is not an error.
+	@returns
+		The parsed value, or the zero value of the type
+		bool True if the value was found in any tier
+		error A *ConfigError if the value that was found could not be parsed (or its reference could not be resolved)
+*/
+
+func (this *configGetter) LookupString(variableName string) (string, bool, error) {
+	config, found, err := this.lookupResolved(variableName)
+	return config.value, found, err
+}
+
+func (this *configGetter) LookupInt(variableName string) (int, bool, error) {
+	config, found, err := this.lookupResolved(variableName)
+	if !found || err != nil {
+		return 0, found, err
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(config.value))
+	if err != nil {
+		return 0, true, parseError(variableName, config, err)
+	}
+	return value, true, nil
+}
+
+func (this *configGetter) LookupBool(variableName string) (bool, bool, error) {
+	config, found, err := this.lookupResolved(variableName)
+	if !found || err != nil {
+		return false, found, err
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(config.value))
+	if err != nil {
+		return false, true, parseError(variableName, config, err)
+	}
+	return value, true, nil
+}
+
+func (this *configGetter) LookupFloat(variableName string) (float64, bool, error) {
+	config, found, err := this.lookupResolved(variableName)
+	if !found || err != nil {
+		return 0, found, err
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(config.value), 64)
+	if err != nil {
+		return 0, true, parseError(variableName, config, err)
+	}
+	return value, true, nil
+}
+
+//Durations use the time.ParseDuration format, e.g. "300ms" or "1h30m"
+func (this *configGetter) LookupDuration(variableName string) (time.Duration, bool, error) {
+	config, found, err := this.lookupResolved(variableName)
+	if !found || err != nil {
+		return 0, found, err
+	}
+	value, err := time.ParseDuration(strings.TrimSpace(config.value))
+	if err != nil {
+		return 0, true, parseError(variableName, config, err)
+	}
+	return value, true, nil
+}
+
+//String slices are comma separated, or a JSON array of strings (which is how arrays in config files are loaded).
+//Each item is trimmed of whitespace and empty items are dropped.
+func (this *configGetter) LookupStringSlice(variableName string) ([]string, bool, error) {
+	config, found, err := this.lookupResolved(variableName)
+	if !found || err != nil {
+		return nil, found, err
+	}
+	return splitConfigList(config.value), true, nil
+}
+
+//splitConfigList splits a comma separated (or JSON array) config value into its trimmed, non-empty items
+func splitConfigList(raw string) []string {
+	list := []string{}
+	if trimmed := strings.TrimSpace(raw); !strings.HasPrefix(trimmed, "[") || json.Unmarshal([]byte(trimmed), &list) != nil {
+		list = strings.Split(raw, ",")
+	}
+
+	items := []string{}
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func notFoundError(variableName string) error {
+	return &ConfigError{Key: variableName, Err: CONFIG_VAR_NOT_FOUND_ERR}
+}
+
+func parseError(variableName string, config configValue, err error) error {
+	//Unwrap strconv's error so the message doesn't repeat the value
+	if num_err, ok := err.(*strconv.NumError); ok {
+		err = num_err.Err
+	}
+	value := config.displayValue()
+	if IsSensitiveConfigKey(variableName) {
+		value = REDACTED_CONFIG_VALUE
+	}
+	return &ConfigError{Key: variableName, Value: value, Source: config.source, Err: err}
+}
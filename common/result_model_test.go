@@ -0,0 +1,447 @@
+package common_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"go.opentelemetry.io/otel/codes"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResultKeepsStructuredFieldsThroughChildrenAndMerges(test *testing.T) {
+	result := common.MakeDefaultCommonResult()
+	result.With("request_id", "abc")
+	result.Infow("Calling dependency", "attempt", 1)
+
+	child := result.GetChild()
+	if fields := child.GetFields(); fields["request_id"] != "abc" {
+		test.Errorf("Expected the child to inherit the result's fields, got %v", fields)
+	}
+
+	dependency_result := common.MakeDefaultCommonResult()
+	dependency_result.With("url", "http://dependency").With("status_code", 502)
+	dependency_result.Errorw("Bad response", "status_code", 503)
+	result.MergeWithResult(dependency_result)
+
+	entries := result.GetEntries()
+	last := entries[len(entries)-1]
+	if last.Level != "Error" || last.Message != "Bad response" {
+		test.Fatalf("Expected the merged entry last, got %+v", last)
+	}
+	if last.Fields["url"] != "http://dependency" || last.Fields["status_code"] != 503 {
+		test.Errorf("Expected the merged entry to keep its result's fields and its own, got %v", last.Fields)
+	}
+	if _, ok := result.GetFields()["url"]; ok {
+		test.Error("Expected merging not to change the result's own fields")
+	}
+	if message := result.Error(); !strings.Contains(message, "Bad response  status_code=503 url=http://dependency") {
+		test.Errorf("Expected the fields in the formatted message, got '%s'", message)
+	}
+
+	first := entries[0]
+	if first.Fields["attempt"] != 1 || first.File != "result_model_test.go" {
+		test.Errorf("Expected the entry's fields and caller, got %+v", first)
+	}
+}
+
+func TestResultFlushesOneJSONDocumentWhenLoggingFormatIsJSON(test *testing.T) {
+	os.Setenv("LOGGING_FORMAT", "json")
+	defer os.Unsetenv("LOGGING_FORMAT")
+	reader, writer, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	result := common.MakeCommonResult(common.GetConfigGetter("testdata/missing.json"))
+	result.With("request_id", "abc")
+	result.Infof("Calling dependency")
+	child := result.GetChild()
+	child.Errorw("Bad response", "status_code", 502)
+	child.Flush()
+	result.Flush()
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		test.Fatal(err)
+	}
+	document := struct {
+		Level   string                 `json:"level"`
+		Fields  map[string]interface{} `json:"fields"`
+		Entries []struct {
+			Level     string                 `json:"level"`
+			Message   string                 `json:"message"`
+			Timestamp string                 `json:"timestamp"`
+			File      string                 `json:"file"`
+			Line      int                    `json:"line"`
+			Child     int                    `json:"child"`
+			Fields    map[string]interface{} `json:"fields"`
+		} `json:"entries"`
+	}{}
+	if err := json.Unmarshal([]byte(line), &document); err != nil {
+		test.Fatalf("Expected a JSON document, got '%s': %v", line, err)
+	}
+	if document.Level != "Error" || document.Fields["request_id"] != "abc" || len(document.Entries) != 4 {
+		test.Fatalf("Unexpected document: %s", line)
+	}
+	first, last := document.Entries[0], document.Entries[3]
+	if first.Message != "Calling dependency" || first.File != "result_model_test.go" || first.Line == 0 || first.Timestamp == "" {
+		test.Errorf("Expected the first entry to have its context, got %+v", first)
+	}
+	if last.Message != "Bad response" || last.Child != 1 || last.Fields["status_code"] != float64(502) {
+		test.Errorf("Expected the child's entry last, got %+v", last)
+	}
+}
+
+func TestResultsWriteToTheirSink(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	result := common.MakeDefaultCommonResult(common.WithResultSink(sink))
+	result.Infof("Hello from the parent")
+	child := result.GetChild()
+	child.Errorf("Hello from the child")
+	child.Flush()
+	result.Flush()
+
+	results, ok := sink.WaitForResults(1, time.Second)
+	if !ok {
+		test.Fatal("Expected the result to be written to the memory sink")
+	}
+	if len(results) != 1 || results[0].Level != common.LEVEL_ERROR || len(results[0].Entries) != 4 {
+		test.Fatalf("Expected one result at the child's error level with every entry, got %+v", results)
+	}
+	if !strings.Contains(results[0].Output, "Hello from the parent") || !strings.Contains(results[0].Output, "Hello from the child") {
+		test.Errorf("Expected the formatted output of the parent and child, got '%s'", results[0].Output)
+	}
+}
+
+func TestSucceedClearsTheErrorsOfEarlierTries(test *testing.T) {
+	result := common.MakeDefaultCommonResult()
+	result.Errorf("Error doing request. Err: %w", context.DeadlineExceeded)
+	result.Succeed()
+
+	if errs := result.Unwrap(); len(errs) != 0 || errors.Is(result, context.DeadlineExceeded) {
+		test.Errorf("Expected a successful result not to keep the errors of its failed tries, got %v", errs)
+	}
+	if len(result.GetMessages()) != 1 {
+		test.Errorf("Expected the failed try to still be logged, got %v", result.GetMessages())
+	}
+}
+
+func TestMergedChildrenReportToTheResultTheyWereMergedInto(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	result := common.MakeDefaultCommonResult(common.WithResultSink(sink))
+	below := common.MakeDefaultCommonResult(common.WithResultSink(sink))
+	child := below.GetChild()
+	result.MergeWithResult(below)
+
+	sentinel := errors.New("child failed")
+	child.Errorf("Hello from the merged child. Err: %w", sentinel)
+	child.Flush()
+	below.Flush()
+	result.Flush()
+
+	if !errors.Is(result, sentinel) || errors.Is(below, sentinel) {
+		test.Error("Expected the child's error to be recorded on the result it was merged into, not its old parent")
+	}
+	results, ok := sink.WaitForResults(2, time.Second)
+	if !ok {
+		test.Fatalf("Expected both results to be flushed, got %d", len(results))
+	}
+	printed := 0
+	for _, flushed := range results {
+		if strings.Contains(flushed.Output, "Hello from the merged child") {
+			printed++
+		}
+	}
+	if printed != 1 {
+		test.Errorf("Expected the merged child to be printed once, got %d times", printed)
+	}
+}
+
+//Run with -race to check the locking
+func TestResultIsSafeToFanOutAcrossGoroutines(test *testing.T) {
+	const workers = 20
+	const logs_per_worker = 10
+	sink := common.GetMemoryResultSink()
+	result := common.MakeDefaultCommonResult(common.WithResultSink(sink))
+
+	var wait_group sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		child := result.GetChild()
+		merged := common.MakeDefaultCommonResult()
+		wait_group.Add(1)
+		go func(worker int) {
+			defer wait_group.Done()
+			for j := 0; j < logs_per_worker; j++ {
+				child.Infof("Worker %d log %d", worker, j)
+				result.Infow("Parent log", "worker", worker)
+			}
+			child.With("worker", worker).Errorf("Worker %d done", worker)
+			child.Flush()
+
+			merged.Infof("Merged from worker %d", worker)
+			result.MergeWithResult(merged)
+			result.GetMessages()
+			result.GetLogLevel()
+		}(i)
+	}
+	wait_group.Wait()
+	result.Flush()
+
+	results, ok := sink.WaitForResults(1, 5*time.Second)
+	if !ok {
+		test.Fatal("Expected the result to be flushed")
+	}
+	//Each worker adds a child marker and its parent logs and merged log to the parent, and a marker, its logs and its
+	//error to the child
+	expected := workers * (1 + logs_per_worker + 1 + 1 + logs_per_worker + 1)
+	if len(results) != 1 || len(results[0].Entries) != expected || results[0].Level != common.LEVEL_ERROR {
+		test.Fatalf("Expected one result with %d entries at the error level, got %d results", expected, len(results))
+	}
+}
+
+func TestResultCanBeLoggedToWhileFlushing(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	result := common.MakeDefaultCommonResult(common.WithResultSink(sink))
+	child := result.GetChild()
+	result.Flush() //Waits on the child in the background
+
+	var wait_group sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wait_group.Add(1)
+		go func(i int) {
+			defer wait_group.Done()
+			result.Infof("Logged while flushing %d", i)
+			child.Infof("Child log %d", i)
+		}(i)
+	}
+	wait_group.Wait()
+	child.Flush()
+
+	results, ok := sink.WaitForResults(1, 5*time.Second)
+	if !ok {
+		test.Fatal("Expected the result to be flushed")
+	}
+	//The parent's marker and logs, and the child's marker and logs
+	if len(results[0].Entries) != 22 {
+		test.Errorf("Expected every entry logged before the child was flushed, got %d", len(results[0].Entries))
+	}
+	if len(result.GetEntries()) != 0 {
+		test.Errorf("Expected the flushed entries to be cleared, got %v", result.GetMessages())
+	}
+}
+
+func TestResultFlushesWithoutChildrenThatDontReportInTime(test *testing.T) {
+	os.Setenv("LOGGING_CHILD_TIMEOUT", "50ms")
+	defer os.Unsetenv("LOGGING_CHILD_TIMEOUT")
+	sink := common.GetMemoryResultSink()
+	result := common.MakeCommonResult(common.GetConfigGetter("testdata/missing.json"), common.WithResultSink(sink))
+	reporting_child := result.GetChild()
+	late_child := result.GetChild()
+	reporting_child.Infof("On time")
+	reporting_child.Flush()
+	result.Flush()
+
+	results, ok := sink.WaitForResults(1, time.Second)
+	if !ok {
+		test.Fatal("Expected the result to be flushed after LOGGING_CHILD_TIMEOUT")
+	}
+	if !strings.Contains(results[0].Output, "On time") || !strings.Contains(results[0].Output, "[CHILDREN #2 DID NOT REPORT]") {
+		test.Errorf("Expected the reporting child's output and a summary of the late child, got '%s'", results[0].Output)
+	}
+
+	late_child.Infof("Too late")
+	late_child.Flush()
+	results, ok = sink.WaitForResults(2, time.Second)
+	if !ok {
+		test.Fatal("Expected the late child to write its own output")
+	}
+	if !strings.Contains(results[1].Output, "Too late") || !strings.Contains(results[1].Output, "[CHILD #2 REPORTED AFTER ITS PARENT WAS FLUSHED]") {
+		test.Errorf("Expected the late child's output, got '%s'", results[1].Output)
+	}
+}
+
+func TestResultFlushesImmediatelyWhenItsContextEnds(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	ctx, cancel := context.WithCancel(context.Background())
+	result := common.MakeCommonResultWithContext(ctx, common.GetConfigGetter("testdata/missing.json"), common.WithResultSink(sink))
+	result.GetChild().Infof("Never flushed")
+	result.Flush()
+	cancel()
+
+	results, ok := sink.WaitForResults(1, time.Second)
+	if !ok {
+		test.Fatal("Expected the result to be flushed as soon as its context was cancelled")
+	}
+	if !strings.Contains(results[0].Output, "[CHILDREN #1 DID NOT REPORT] Flushed without them because the context ended") {
+		test.Errorf("Expected a summary of the child that didn't report, got '%s'", results[0].Output)
+	}
+}
+
+func TestResultLevelsFilterWhatIsRecordedAndPrinted(test *testing.T) {
+	os.Setenv("LOGGING_LEVEL", "WARN")
+	os.Setenv("LOGGING_FLUSH_LEVEL", "ERROR")
+	defer os.Unsetenv("LOGGING_LEVEL")
+	defer os.Unsetenv("LOGGING_FLUSH_LEVEL")
+	sink := common.GetMemoryResultSink()
+	configs := common.GetConfigGetter("testdata/missing.json")
+
+	warning := common.MakeCommonResult(configs, common.WithResultSink(sink))
+	warning.Debugf("Not recorded")
+	warning.Infof("Not recorded either")
+	warning.Warnf("Bad response code")
+	if warning.GetLogLevel() != common.LEVEL_WARN || len(warning.GetEntries()) != 1 {
+		test.Errorf("Expected only the warning to be recorded, got %s %v", warning.GetLogLevel(), warning.GetMessages())
+	}
+	warning.Flush()
+
+	fatal := common.MakeCommonResult(configs, common.WithResultSink(sink))
+	fatal.Warnf("Bad response code")
+	fatal.Fatalf("Out of retries")
+	fatal.Flush()
+
+	sink.WaitForResults(1, time.Second)
+	time.Sleep(50 * time.Millisecond) //Give the warning's Flush a chance to (wrongly) print
+	results := sink.GetResults()
+	if len(results) != 1 || results[0].Level != common.LEVEL_FATAL || !strings.Contains(results[0].Output, "[Fatal] Out of retries") {
+		test.Errorf("Expected only the result that reached LOGGING_FLUSH_LEVEL to be printed, got %+v", results)
+	}
+}
+
+func TestParseLevel(test *testing.T) {
+	for name, expected := range map[string]common.Level{
+		"DEBUG": common.LEVEL_DEBUG, "info": common.LEVEL_INFO, "Warning": common.LEVEL_WARN, "WARN": common.LEVEL_WARN,
+		"ERROR": common.LEVEL_ERROR, "FATAL": common.LEVEL_FATAL,
+	} {
+		if level, ok := common.ParseLevel(name); !ok || level != expected {
+			test.Errorf("Expected %s to parse as %s, got %s", name, expected, level)
+		}
+	}
+	if _, ok := common.ParseLevel("LOUD"); ok {
+		test.Error("Expected LOUD not to parse")
+	}
+}
+
+func TestLevelsAreNumberedInOrder(test *testing.T) {
+	ordered := []common.Level{common.LEVEL_DEBUG, common.LEVEL_INFO, common.LEVEL_WARN, common.LEVEL_ERROR, common.LEVEL_FATAL}
+	for i, level := range ordered {
+		if int(level) != i {
+			test.Errorf("Expected %s to be %d, got %d", level, i, int(level))
+		}
+		for j, other := range ordered {
+			if level.AtLeast(other) != (i >= j) || (level >= other) != (i >= j) {
+				test.Errorf("Expected %s >= %s to be %t", level, other, i >= j)
+			}
+		}
+	}
+}
+
+func TestResultKeepsItsErrorChainThroughChildrenAndMerges(test *testing.T) {
+	var syntax_err *json.SyntaxError
+	decode_err := json.Unmarshal([]byte("{"), &struct{}{})
+	if !errors.As(decode_err, &syntax_err) {
+		test.Fatalf("Expected a json.SyntaxError, got %v", decode_err)
+	}
+
+	result := common.MakeDefaultCommonResult()
+	child := result.GetChild()
+	child.Errorf("Request failed. Err: %w", context.DeadlineExceeded)
+
+	below := common.MakeDefaultCommonResult()
+	below.Errorw("Bad response.Body", "url", "http://example.com", "err", decode_err)
+	result.MergeWithResult(below)
+
+	sentinel := errors.New("expired")
+	result.Fail(sentinel, nil)
+	result.Errorf("Not wrapped: %v", errors.New("plain"))
+
+	if result.WasSuccessful() {
+		test.Error("Expected Fail to mark the result as unsuccessful")
+	}
+	if errs := result.Unwrap(); len(errs) != 3 {
+		test.Errorf("Expected the child's, merged and failed errors, got %v", errs)
+	}
+	if !errors.Is(result, context.DeadlineExceeded) || !errors.Is(result, sentinel) {
+		test.Error("Expected errors.Is to find the child's and failed errors")
+	}
+	var found *json.SyntaxError
+	if !errors.As(result, &found) || found != syntax_err {
+		test.Errorf("Expected errors.As to find the merged json.SyntaxError, got %v", found)
+	}
+	if !result.Is(context.DeadlineExceeded) || result.Is(errors.New("expired")) {
+		test.Error("Expected Is to match the recorded errors without relying on errors.Is following Unwrap() []error")
+	}
+	if !strings.Contains(result.GetMessages()[len(result.GetMessages())-1], "Not wrapped: plain") {
+		test.Errorf("Expected Errorf to format the message as before, got %v", result.GetMessages())
+	}
+}
+
+//spanRecorder keeps the spans ended by a test tracer
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []*export.SpanData
+}
+
+func (this *spanRecorder) ExportSpan(ctx context.Context, span *export.SpanData) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.spans = append(this.spans, span)
+}
+
+func TestResultCarriesTheTraceOfItsContextAndRecordsErrorsOnTheSpan(test *testing.T) {
+	os.Setenv("LOGGING_TRACE_ERRORS", "true")
+	defer os.Unsetenv("LOGGING_TRACE_ERRORS")
+	os.Setenv("LOGGING_LEVEL", "DEBUG")
+	defer os.Unsetenv("LOGGING_LEVEL")
+	recorder := &spanRecorder{}
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(recorder),
+	)
+	if err != nil {
+		test.Fatal(err)
+	}
+	ctx, span := provider.Tracer("test").Start(context.Background(), "GET /things/{id}")
+	trace_id := span.SpanContext().TraceID.String()
+	span_id := span.SpanContext().SpanID.String()
+
+	sink := common.GetMemoryResultSink()
+	result := common.MakeCommonResultWithContext(ctx, common.GetConfigGetter("testdata/missing.json"), common.WithResultSink(sink))
+	result.Infof("Getting thing")
+	result.DebugMessagef("Starting req")
+	child := result.GetChild()
+	child.Errorf("Bad response. Err: %w", context.DeadlineExceeded)
+	child.Flush()
+	span.End()
+
+	for _, entry := range result.GetEntries() {
+		if entry.TraceID != trace_id || entry.SpanID != span_id {
+			test.Errorf("Expected every entry to carry the span's IDs, got %+v", entry)
+		}
+	}
+	result.Flush()
+	results, ok := sink.WaitForResults(1, time.Second)
+	if !ok {
+		test.Fatal("Expected the result to be flushed")
+	}
+	if results[0].Fields[common.RESULT_FIELD_TRACE_ID] != trace_id || !strings.Contains(results[0].Output, "trace_id="+trace_id) {
+		test.Errorf("Expected the flushed output to carry the trace ID, got '%s'", results[0].Output)
+	}
+
+	if len(recorder.spans) != 1 {
+		test.Fatalf("Expected the span to be exported, got %d spans", len(recorder.spans))
+	}
+	exported := recorder.spans[0]
+	if uint32(exported.StatusCode) != uint32(codes.Unknown) || len(exported.MessageEvents) != 1 ||
+		!strings.Contains(exported.StatusMessage, "context deadline exceeded") {
+		test.Errorf("Expected the child's Errorf as an error event and status, got %v %s %+v",
+			exported.StatusCode, exported.StatusMessage, exported.MessageEvents)
+	}
+}
@@ -0,0 +1,44 @@
+package flags
+
+import (
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"net/http"
+)
+
+//SubjectFunc gets the subject of a request (e.g. from its JWT claims) for evaluating flags
+type SubjectFunc func(r *http.Request) Subject
+
+/*
+	FlagMiddleware returns a routing.MiddleWare that only lets a request through if the flag is on for the request's
+	subject. Otherwise it responds with status_code, usually http.StatusNotFound (to hide that the route exists) or
+	http.StatusForbidden.
+	@params
+		flags IFlags The flags to evaluate
+		flag string The name of the flag that gates the route
+		subject_func SubjectFunc Gets the subject of the request. If nil, requests are evaluated as an anonymous subject.
+		status_code int The status code to respond with when the flag is off
+*/
+func FlagMiddleware(flags IFlags, flag string, subject_func SubjectFunc, status_code int) routing.MiddleWare {
+	return func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			subject := Subject{}
+			if subject_func != nil {
+				subject = subject_func(r)
+			}
+			if !flags.IsEnabled(r.Context(), flag, subject) {
+				w.WriteHeader(status_code)
+				return
+			}
+			h(w, r)
+		}
+	}
+}
+
+/*
+	GateRoute returns a copy of route whose handler is wrapped in FlagMiddleware, ready to be registered with a
+	routing.CustomRouter. See FlagMiddleware for the params.
+*/
+func GateRoute(route routing.Route, flags IFlags, flag string, subject_func SubjectFunc, status_code int) routing.Route {
+	route.HandlerFunc = FlagMiddleware(flags, flag, subject_func, status_code)(route.HandlerFunc)
+	return route
+}
@@ -0,0 +1,181 @@
+package flags
+
+import (
+	"context"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/label"
+	"hash/fnv"
+)
+
+/*
+	Feature flags are defined in config under the "flags." prefix. The simplest flag is a boolean:
+		{"flags": {"new_checkout": true}}
+	A flag can also be rolled out to a percentage of subjects, and/or to allow-lists of org IDs and IDM GUIDs (the
+	same IDs that are tagged on spans as routing.SPAN_TAG_KEY_ORG_ID and routing.SPAN_TAG_KEY_IDM_GUID):
+		{"flags": {"new_checkout": {
+			"enabled": true,           Optional, false turns the flag off for everyone
+			"percentage": 25,          The percentage of subjects (0-100) the flag is on for
+			"org_ids": ["42"],         Orgs the flag is always on for
+			"idm_guids": ["abc-123"]   Users the flag is always on for
+		}}}
+	As with any config var, these can be overridden with environment variables, e.g. FLAGS_NEW_CHECKOUT_PERCENTAGE=50.
+	A flag that isn't defined is off. A flag with allow-lists but no percentage is only on for the listed subjects, and
+	a flag with neither is on for everyone (unless "enabled" is false).
+
+	Percentage rollouts are deterministic: a subject (by IDM GUID, or org ID if it has no IDM GUID) always gets the same
+	result for a flag, and raising the percentage only adds subjects.
+*/
+const FLAG_CONFIG_PREFIX = "flags."
+
+//The reasons an Evaluation can have
+const (
+	REASON_UNDEFINED      = "undefined"      //The flag isn't defined in config
+	REASON_INVALID_CONFIG = "invalid_config" //The flag's config couldn't be parsed, so it is off
+	REASON_DISABLED       = "disabled"       //The flag is turned off
+	REASON_ENABLED        = "enabled"        //The flag is on for everyone
+	REASON_ORG_ID         = "org_id"         //The subject's org ID is in the flag's allow-list
+	REASON_IDM_GUID       = "idm_guid"       //The subject's IDM GUID is in the flag's allow-list
+	REASON_PERCENTAGE     = "percentage"     //The subject was (or wasn't) picked by the flag's percentage rollout
+	REASON_NOT_ALLOWED    = "not_allowed"    //The flag is only on for its allow-lists, which don't include the subject
+)
+
+//The span event recorded for each evaluation, see IFlags.Evaluate
+const SPAN_EVENT_FEATURE_FLAG = "feature_flag"
+const SPAN_TAG_KEY_FLAG_KEY = "feature_flag.key"
+const SPAN_TAG_KEY_FLAG_ENABLED = "feature_flag.enabled"
+const SPAN_TAG_KEY_FLAG_REASON = "feature_flag.reason"
+
+//Subject is who a flag is being evaluated for. Either ID may be empty.
+type Subject struct {
+	OrgID   string
+	IdmGUID string
+}
+
+//Evaluation is the result of evaluating a flag for a subject
+type Evaluation struct {
+	Flag    string
+	Enabled bool
+	Reason  string //One of the REASON_ constants
+}
+
+/*
+	This class is used for evaluating feature flags that are defined in config, see FLAG_CONFIG_PREFIX.
+	Flags are read from the IConfigGetter on every evaluation, so a watched getter can turn them on and off without a
+	restart.
+*/
+type IFlags interface {
+	IsEnabled(ctx context.Context, flag string, subject Subject) bool
+	Evaluate(ctx context.Context, flag string, subject Subject) Evaluation
+}
+
+//Implements IFlags
+type flags struct {
+	configs common.IConfigGetter
+}
+
+/*
+	Returns an implementation of IFlags
+	@params
+		configs common.IConfigGetter The config getter the flags are defined in
+*/
+func GetFlags(configs common.IConfigGetter) IFlags {
+	return &flags{
+		configs: configs,
+	}
+}
+
+//IsEnabled returns true if the flag is on for the subject. See Evaluate.
+func (this *flags) IsEnabled(ctx context.Context, flag string, subject Subject) bool {
+	return this.Evaluate(ctx, flag, subject).Enabled
+}
+
+/*
+	Evaluate works out whether a flag is on for the subject, and why. The evaluation is recorded as a
+	SPAN_EVENT_FEATURE_FLAG event on the span in ctx (if there is one).
+	@params
+		ctx context.Context The request's context
+		flag string The name of the flag, without FLAG_CONFIG_PREFIX
+		subject Subject Who the flag is being evaluated for
+*/
+func (this *flags) Evaluate(ctx context.Context, flag string, subject Subject) Evaluation {
+	enabled, reason := this.evaluate(flag, subject)
+	evaluation := Evaluation{Flag: flag, Enabled: enabled, Reason: reason}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(label.Bool(FLAG_CONFIG_PREFIX+flag, enabled))
+	span.AddEvent(ctx, SPAN_EVENT_FEATURE_FLAG,
+		label.String(SPAN_TAG_KEY_FLAG_KEY, flag),
+		label.Bool(SPAN_TAG_KEY_FLAG_ENABLED, enabled),
+		label.String(SPAN_TAG_KEY_FLAG_REASON, reason),
+		label.String(routing.SPAN_TAG_KEY_ORG_ID, subject.OrgID),
+		label.String(routing.SPAN_TAG_KEY_IDM_GUID, subject.IdmGUID),
+	)
+	return evaluation
+}
+
+func (this *flags) evaluate(flag string, subject Subject) (bool, string) {
+	key := FLAG_CONFIG_PREFIX + flag
+	if on, found, err := this.configs.LookupBool(key); err != nil {
+		return false, REASON_INVALID_CONFIG
+	} else if found {
+		if on {
+			return true, REASON_ENABLED
+		}
+		return false, REASON_DISABLED
+	}
+
+	enabled, enabled_found, enabled_err := this.configs.LookupBool(key + ".enabled")
+	percentage, percentage_found, percentage_err := this.configs.LookupFloat(key + ".percentage")
+	org_ids, org_ids_found, org_ids_err := this.configs.LookupStringSlice(key + ".org_ids")
+	idm_guids, idm_guids_found, idm_guids_err := this.configs.LookupStringSlice(key + ".idm_guids")
+	if enabled_err != nil || percentage_err != nil || org_ids_err != nil || idm_guids_err != nil {
+		return false, REASON_INVALID_CONFIG
+	}
+
+	switch {
+	case !enabled_found && !percentage_found && !org_ids_found && !idm_guids_found:
+		return false, REASON_UNDEFINED
+	case enabled_found && !enabled:
+		return false, REASON_DISABLED
+	case subject.OrgID != "" && contains(org_ids, subject.OrgID):
+		return true, REASON_ORG_ID
+	case subject.IdmGUID != "" && contains(idm_guids, subject.IdmGUID):
+		return true, REASON_IDM_GUID
+	case percentage_found:
+		return percentage >= 100 || rolloutBucket(flag, subject) < percentage, REASON_PERCENTAGE
+	case org_ids_found || idm_guids_found:
+		return false, REASON_NOT_ALLOWED
+	default:
+		return true, REASON_ENABLED
+	}
+}
+
+/*
+	rolloutBucket places the subject in one of 10,000 buckets for the flag, returned as a percentage in [0, 100).
+	Hashing the flag name with the subject means each flag picks a different set of subjects.
+*/
+func rolloutBucket(flag string, subject Subject) float64 {
+	subject_key := subject.IdmGUID
+	if subject_key == "" {
+		subject_key = subject.OrgID
+	}
+	if subject_key == "" {
+		//Anonymous subjects are only included in a 100% rollout
+		return 100
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(flag + ":" + subject_key))
+	return float64(hash.Sum32()%10000) / 100
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package flags_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/BrandonEchols/common-go-utils/flags"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func getTestFlags(test *testing.T, contents string) (flags.IFlags, func()) {
+	dir, _ := ioutil.TempDir("", "flags_test")
+	config_file := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(config_file, []byte(contents), 0644); err != nil {
+		test.Fatal(err)
+	}
+	return flags.GetFlags(common.GetConfigGetter(config_file)), func() { os.RemoveAll(dir) }
+}
+
+func TestFlagsEvaluateConfiguredRules(test *testing.T) {
+	feature_flags, cleanup := getTestFlags(test, `{"flags": {
+		"simple": true,
+		"killed": {"enabled": false, "org_ids": ["42"]},
+		"allow_listed": {"org_ids": ["42"], "idm_guids": ["abc"]},
+		"everyone": {"percentage": 100},
+		"bad": {"percentage": "lots"}
+	}}`)
+	defer cleanup()
+
+	cases := []struct {
+		flag    string
+		subject flags.Subject
+		enabled bool
+		reason  string
+	}{
+		{"simple", flags.Subject{}, true, flags.REASON_ENABLED},
+		{"missing", flags.Subject{OrgID: "42"}, false, flags.REASON_UNDEFINED},
+		{"killed", flags.Subject{OrgID: "42"}, false, flags.REASON_DISABLED},
+		{"allow_listed", flags.Subject{OrgID: "42"}, true, flags.REASON_ORG_ID},
+		{"allow_listed", flags.Subject{OrgID: "7", IdmGUID: "abc"}, true, flags.REASON_IDM_GUID},
+		{"allow_listed", flags.Subject{OrgID: "7"}, false, flags.REASON_NOT_ALLOWED},
+		{"everyone", flags.Subject{}, true, flags.REASON_PERCENTAGE},
+		{"bad", flags.Subject{OrgID: "42"}, false, flags.REASON_INVALID_CONFIG},
+	}
+	for _, c := range cases {
+		evaluation := feature_flags.Evaluate(context.Background(), c.flag, c.subject)
+		if evaluation.Enabled != c.enabled || evaluation.Reason != c.reason {
+			test.Errorf("%s for %+v: expected %t (%s), got %t (%s)",
+				c.flag, c.subject, c.enabled, c.reason, evaluation.Enabled, evaluation.Reason)
+		}
+	}
+}
+
+func TestFlagsPercentageRolloutIsDeterministic(test *testing.T) {
+	feature_flags, cleanup := getTestFlags(test, `{"flags": {"half": {"percentage": 50}}}`)
+	defer cleanup()
+
+	enabled_count := 0
+	for i := 0; i < 1000; i++ {
+		subject := flags.Subject{IdmGUID: fmt.Sprintf("user-%d", i)}
+		enabled := feature_flags.IsEnabled(context.Background(), "half", subject)
+		if enabled != feature_flags.IsEnabled(context.Background(), "half", subject) {
+			test.Fatalf("Expected the same result for %s every time", subject.IdmGUID)
+		}
+		if enabled {
+			enabled_count++
+		}
+	}
+	if enabled_count < 400 || enabled_count > 600 {
+		test.Errorf("Expected about half of the subjects to be enabled, got %d of 1000", enabled_count)
+	}
+}
+
+func TestGateRouteRespondsWithStatusCodeWhenFlagIsOff(test *testing.T) {
+	feature_flags, cleanup := getTestFlags(test, `{"flags": {"beta": {"org_ids": ["42"]}}}`)
+	defer cleanup()
+
+	route := flags.GateRoute(routing.Route{
+		Method:      "GET",
+		Path:        "/beta",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) },
+	}, feature_flags, "beta", func(r *http.Request) flags.Subject {
+		return flags.Subject{OrgID: r.Header.Get("X-Org-Id")}
+	}, http.StatusNotFound)
+
+	for org_id, expected := range map[string]int{"42": http.StatusOK, "7": http.StatusNotFound} {
+		req := httptest.NewRequest("GET", "/beta", nil)
+		req.Header.Set("X-Org-Id", org_id)
+		response := httptest.NewRecorder()
+		route.HandlerFunc(response, req)
+		if response.Code != expected {
+			test.Errorf("Org %s: expected status %d, got %d", org_id, expected, response.Code)
+		}
+	}
+}
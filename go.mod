@@ -3,6 +3,7 @@ module github.com/BrandonEchols/common-go-utils
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/golang/mock v1.4.3
 	github.com/gorilla/handlers v1.4.2
@@ -12,4 +13,5 @@ require (
 	go.opentelemetry.io/otel/exporters/trace/jaeger v0.11.0
 	go.opentelemetry.io/otel/sdk v0.11.0
 	go.uber.org/zap v1.13.0
+	gopkg.in/yaml.v2 v2.2.2
 )
@@ -1,49 +1,346 @@
-// Automatically generated by MockGen. DO NOT EDIT!
+// Code generated by MockGen. DO NOT EDIT.
 // Source: common/ConfigGetter.go
 
+// Package mocks is a generated GoMock package.
 package mocks
 
 import (
-	"github.com/golang/mock/gomock"
+	common "github.com/BrandonEchols/common-go-utils/common"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
 )
 
-// Mock of IConfigGetter interface
+// MockIConfigGetter is a mock of IConfigGetter interface
 type MockIConfigGetter struct {
 	ctrl     *gomock.Controller
-	recorder *_MockIConfigGetterRecorder
+	recorder *MockIConfigGetterMockRecorder
 }
 
-// Recorder for MockIConfigGetter (not exported)
-type _MockIConfigGetterRecorder struct {
+// MockIConfigGetterMockRecorder is the mock recorder for MockIConfigGetter
+type MockIConfigGetterMockRecorder struct {
 	mock *MockIConfigGetter
 }
 
+// NewMockIConfigGetter creates a new mock instance
 func NewMockIConfigGetter(ctrl *gomock.Controller) *MockIConfigGetter {
 	mock := &MockIConfigGetter{ctrl: ctrl}
-	mock.recorder = &_MockIConfigGetterRecorder{mock}
+	mock.recorder = &MockIConfigGetterMockRecorder{mock}
 	return mock
 }
 
-func (_m *MockIConfigGetter) EXPECT() *_MockIConfigGetterRecorder {
-	return _m.recorder
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockIConfigGetter) EXPECT() *MockIConfigGetterMockRecorder {
+	return m.recorder
 }
 
-func (_m *MockIConfigGetter) MustGetConfigVar(variableName string) string {
-	ret := _m.ctrl.Call(_m, "MustGetConfigVar", variableName)
+// MustGetConfigVar mocks base method
+func (m *MockIConfigGetter) MustGetConfigVar(variableName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MustGetConfigVar", variableName)
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-func (_mr *_MockIConfigGetterRecorder) MustGetConfigVar(arg0 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "MustGetConfigVar", arg0)
+// MustGetConfigVar indicates an expected call of MustGetConfigVar
+func (mr *MockIConfigGetterMockRecorder) MustGetConfigVar(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MustGetConfigVar", reflect.TypeOf((*MockIConfigGetter)(nil).MustGetConfigVar), variableName)
 }
 
-func (_m *MockIConfigGetter) SafeGetConfigVar(variableName string) string {
-	ret := _m.ctrl.Call(_m, "SafeGetConfigVar", variableName)
+// SafeGetConfigVar mocks base method
+func (m *MockIConfigGetter) SafeGetConfigVar(variableName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SafeGetConfigVar", variableName)
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-func (_mr *_MockIConfigGetterRecorder) SafeGetConfigVar(arg0 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "SafeGetConfigVar", arg0)
+// SafeGetConfigVar indicates an expected call of SafeGetConfigVar
+func (mr *MockIConfigGetterMockRecorder) SafeGetConfigVar(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeGetConfigVar", reflect.TypeOf((*MockIConfigGetter)(nil).SafeGetConfigVar), variableName)
+}
+
+// LookupConfigVar mocks base method
+func (m *MockIConfigGetter) LookupConfigVar(variableName string) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupConfigVar", variableName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// LookupConfigVar indicates an expected call of LookupConfigVar
+func (mr *MockIConfigGetterMockRecorder) LookupConfigVar(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupConfigVar", reflect.TypeOf((*MockIConfigGetter)(nil).LookupConfigVar), variableName)
+}
+
+// GetString mocks base method
+func (m *MockIConfigGetter) GetString(variableName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetString", variableName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetString indicates an expected call of GetString
+func (mr *MockIConfigGetterMockRecorder) GetString(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetString", reflect.TypeOf((*MockIConfigGetter)(nil).GetString), variableName)
+}
+
+// GetInt mocks base method
+func (m *MockIConfigGetter) GetInt(variableName string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInt", variableName)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInt indicates an expected call of GetInt
+func (mr *MockIConfigGetterMockRecorder) GetInt(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInt", reflect.TypeOf((*MockIConfigGetter)(nil).GetInt), variableName)
+}
+
+// GetBool mocks base method
+func (m *MockIConfigGetter) GetBool(variableName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBool", variableName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBool indicates an expected call of GetBool
+func (mr *MockIConfigGetterMockRecorder) GetBool(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBool", reflect.TypeOf((*MockIConfigGetter)(nil).GetBool), variableName)
+}
+
+// GetFloat mocks base method
+func (m *MockIConfigGetter) GetFloat(variableName string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFloat", variableName)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFloat indicates an expected call of GetFloat
+func (mr *MockIConfigGetterMockRecorder) GetFloat(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFloat", reflect.TypeOf((*MockIConfigGetter)(nil).GetFloat), variableName)
+}
+
+// GetDuration mocks base method
+func (m *MockIConfigGetter) GetDuration(variableName string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDuration", variableName)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDuration indicates an expected call of GetDuration
+func (mr *MockIConfigGetterMockRecorder) GetDuration(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDuration", reflect.TypeOf((*MockIConfigGetter)(nil).GetDuration), variableName)
+}
+
+// GetStringSlice mocks base method
+func (m *MockIConfigGetter) GetStringSlice(variableName string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringSlice", variableName)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStringSlice indicates an expected call of GetStringSlice
+func (mr *MockIConfigGetterMockRecorder) GetStringSlice(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringSlice", reflect.TypeOf((*MockIConfigGetter)(nil).GetStringSlice), variableName)
+}
+
+// LookupString mocks base method
+func (m *MockIConfigGetter) LookupString(variableName string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupString", variableName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupString indicates an expected call of LookupString
+func (mr *MockIConfigGetterMockRecorder) LookupString(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupString", reflect.TypeOf((*MockIConfigGetter)(nil).LookupString), variableName)
+}
+
+// LookupInt mocks base method
+func (m *MockIConfigGetter) LookupInt(variableName string) (int, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupInt", variableName)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupInt indicates an expected call of LookupInt
+func (mr *MockIConfigGetterMockRecorder) LookupInt(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupInt", reflect.TypeOf((*MockIConfigGetter)(nil).LookupInt), variableName)
+}
+
+// LookupBool mocks base method
+func (m *MockIConfigGetter) LookupBool(variableName string) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupBool", variableName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupBool indicates an expected call of LookupBool
+func (mr *MockIConfigGetterMockRecorder) LookupBool(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupBool", reflect.TypeOf((*MockIConfigGetter)(nil).LookupBool), variableName)
+}
+
+// LookupFloat mocks base method
+func (m *MockIConfigGetter) LookupFloat(variableName string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupFloat", variableName)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupFloat indicates an expected call of LookupFloat
+func (mr *MockIConfigGetterMockRecorder) LookupFloat(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupFloat", reflect.TypeOf((*MockIConfigGetter)(nil).LookupFloat), variableName)
+}
+
+// LookupDuration mocks base method
+func (m *MockIConfigGetter) LookupDuration(variableName string) (time.Duration, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupDuration", variableName)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupDuration indicates an expected call of LookupDuration
+func (mr *MockIConfigGetterMockRecorder) LookupDuration(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupDuration", reflect.TypeOf((*MockIConfigGetter)(nil).LookupDuration), variableName)
+}
+
+// LookupStringSlice mocks base method
+func (m *MockIConfigGetter) LookupStringSlice(variableName string) ([]string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupStringSlice", variableName)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupStringSlice indicates an expected call of LookupStringSlice
+func (mr *MockIConfigGetterMockRecorder) LookupStringSlice(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupStringSlice", reflect.TypeOf((*MockIConfigGetter)(nil).LookupStringSlice), variableName)
+}
+
+// Reload mocks base method
+func (m *MockIConfigGetter) Reload() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reload")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reload indicates an expected call of Reload
+func (mr *MockIConfigGetterMockRecorder) Reload() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockIConfigGetter)(nil).Reload))
+}
+
+// Watch mocks base method
+func (m *MockIConfigGetter) Watch(interval time.Duration) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", interval)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockIConfigGetterMockRecorder) Watch(interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockIConfigGetter)(nil).Watch), interval)
+}
+
+// Subscribe mocks base method
+func (m *MockIConfigGetter) Subscribe(variableName string, callback func(string, string)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Subscribe", variableName, callback)
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockIConfigGetterMockRecorder) Subscribe(variableName, callback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockIConfigGetter)(nil).Subscribe), variableName, callback)
+}
+
+// IsSecret mocks base method
+func (m *MockIConfigGetter) IsSecret(variableName string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSecret", variableName)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsSecret indicates an expected call of IsSecret
+func (mr *MockIConfigGetterMockRecorder) IsSecret(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSecret", reflect.TypeOf((*MockIConfigGetter)(nil).IsSecret), variableName)
+}
+
+// GetConfigSource mocks base method
+func (m *MockIConfigGetter) GetConfigSource(variableName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigSource", variableName)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetConfigSource indicates an expected call of GetConfigSource
+func (mr *MockIConfigGetterMockRecorder) GetConfigSource(variableName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigSource", reflect.TypeOf((*MockIConfigGetter)(nil).GetConfigSource), variableName)
+}
+
+// GetConfigReport mocks base method
+func (m *MockIConfigGetter) GetConfigReport() []common.ConfigEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigReport")
+	ret0, _ := ret[0].([]common.ConfigEntry)
+	return ret0
+}
+
+// GetConfigReport indicates an expected call of GetConfigReport
+func (mr *MockIConfigGetterMockRecorder) GetConfigReport() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigReport", reflect.TypeOf((*MockIConfigGetter)(nil).GetConfigReport))
 }
@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"github.com/BrandonEchols/common-go-utils/common"
 	"github.com/gorilla/handlers"
 	"net/http"
 )
@@ -20,3 +21,35 @@ func CorsMiddleware(h http.Handler, origins []string, methods []string, headers
 
 	return handlers.CORS(originsOk, methodsOk, headersOk)(h)
 }
+
+/*
+	CorsMiddlewareFromConfig is the same as CorsMiddleware, but the allowed origins are read from a config var on each
+	request, so they can be changed without a restart when the IConfigGetter is being watched (see IConfigGetter.Watch).
+	@params
+		h http.Handler The handler to wrap
+		configs common.IConfigGetter The config getter to read the origins from
+		origins_key string The config var holding a comma separated list of origins to allow. "*" allows any origin
+		methods []string An array of methods to allow
+		headers []string An array of headers to allow
+*/
+func CorsMiddlewareFromConfig(
+	h http.Handler,
+	configs common.IConfigGetter,
+	origins_key string,
+	methods []string,
+	headers []string,
+) http.Handler {
+	originsOk := handlers.AllowedOriginValidator(func(origin string) bool {
+		origins, _, _ := configs.LookupStringSlice(origins_key)
+		for _, allowed := range origins {
+			if allowed == "*" || allowed == origin {
+				return true
+			}
+		}
+		return false
+	})
+	methodsOk := handlers.AllowedMethods(methods)
+	headersOk := handlers.AllowedHeaders(headers)
+
+	return handlers.CORS(originsOk, methodsOk, headersOk)(h)
+}
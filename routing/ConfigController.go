@@ -0,0 +1,72 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"net/http"
+)
+
+/*
+	This class is used for hosting an endpoint that reports the effective configuration of the service, which is useful
+	for debugging which config values a pod is actually using. The report includes every config value, so it should only
+	be registered behind admin/authentication middleware.
+*/
+type IConfigController interface {
+	GetConfig(w http.ResponseWriter, r *http.Request)
+}
+
+//Implements IConfigController
+type configController struct {
+	configs common.IConfigGetter
+}
+
+/*
+	Returns an implementation of IConfigController
+	@params
+		configs common.IConfigGetter The config getter to report on
+*/
+func GetConfigController(configs common.IConfigGetter) IConfigController {
+	return &configController{
+		configs: configs,
+	}
+}
+
+//The response body of GetConfig
+type configReportResponse struct {
+	Configs []common.ConfigEntry `json:"configs"`
+}
+
+/*
+	This API endpoint returns the JSON report from IConfigGetter.GetConfigReport. Secret values, and the values of keys
+	that match common.SENSITIVE_CONFIG_KEY_PATTERNS, are replaced with common.REDACTED_CONFIG_VALUE. Their errors are replaced
+	too, since an error can quote the value it failed on.
+
+	Sample response:
+	{"configs": [
+		{"key": "JWT_PRIVATE_KEY", "value": "[REDACTED]", "source": "env", "secret": false},
+		{"key": "LOGGING_LEVEL", "value": "DEBUG", "source": "config/config.json", "secret": false}
+	]}
+*/
+func (this *configController) GetConfig(w http.ResponseWriter, r *http.Request) {
+	entries := this.configs.GetConfigReport()
+	for i, entry := range entries {
+		if entry.Secret || common.IsSensitiveConfigKey(entry.Key) {
+			entries[i].Value = common.REDACTED_CONFIG_VALUE
+			if entry.Error != "" {
+				entries[i].Error = fmt.Sprintf("config var %s from %s: invalid value %s", entry.Key, entry.Source, common.REDACTED_CONFIG_VALUE)
+			}
+		}
+	}
+
+	result, err := json.Marshal(configReportResponse{Configs: entries})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(result)
+}
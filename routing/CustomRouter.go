@@ -151,6 +151,14 @@ func (this *HTTPResponseWriterWrapper) WriteHeader(code int) {
 	this.ResponseWriter.WriteHeader(code)
 }
 
+//Write records the implicit 200 status code when the handler writes a body without calling WriteHeader
+func (this *HTTPResponseWriterWrapper) Write(data []byte) (int, error) {
+	if this.status_code == 0 {
+		this.status_code = http.StatusOK
+	}
+	return this.ResponseWriter.Write(data)
+}
+
 func getTraceCode(respCode int) codes.Code {
 	switch respCode {
 	case 400:
@@ -14,22 +14,43 @@ const SPAN_TAG_KEY_MESSAGE = "message"
 const SPAN_TAG_KEY_RESPONSE_BODY = "http.response.body"
 const SPAN_TAG_KEY_STATUS_CODE = "http.status_code"
 
-func InitializeJaegerTracer(configs common.IConfigGetter) (func(), error) {
+//The configs needed by InitializeJaegerTracer
+type jaegerConfig struct {
+	CollectorEndpoint string `config:"JAEGER_COLLECTOR_ENDPOINT" required:"true"`
+	ServiceName       string `config:"JAEGER_SERVICE_NAME" required:"true"`
+	PodID             string `config:"JAEGER_POD_ID" required:"true"`
+}
 
-	collectorEndpoint := configs.MustGetConfigVar("JAEGER_COLLECTOR_ENDPOINT")
-	serviceName := configs.MustGetConfigVar("JAEGER_SERVICE_NAME")
-	podID := configs.MustGetConfigVar("JAEGER_POD_ID")
+/*
+	InitializeJaegerTracer installs a jaeger exporter as the global trace provider.
+	@params
+		configs common.IConfigGetter Used to load JAEGER_COLLECTOR_ENDPOINT, JAEGER_SERVICE_NAME and JAEGER_POD_ID
+	@returns
+		func() Flushes any spans that have not been exported yet, or does nothing if there is an error
+		error A common.ConfigErrors listing every missing config var, or the error from installing the pipeline
+*/
+func InitializeJaegerTracer(configs common.IConfigGetter) (func(), error) {
+	jaeger_configs := jaegerConfig{}
+	if err := common.LoadInto(configs, &jaeger_configs); err != nil {
+		return func() {}, err
+	}
 
 	// Create the a jaeger exporter
 	flush, err := jaeger.InstallNewPipeline(
-		jaeger.WithCollectorEndpoint(collectorEndpoint),
+		jaeger.WithCollectorEndpoint(jaeger_configs.CollectorEndpoint),
 		jaeger.WithProcess(jaeger.Process{
-			ServiceName: serviceName,
-			Tags:        []label.KeyValue{label.String("POD_ID", podID), label.String("service", serviceName)},
+			ServiceName: jaeger_configs.ServiceName,
+			Tags: []label.KeyValue{
+				label.String("POD_ID", jaeger_configs.PodID),
+				label.String("service", jaeger_configs.ServiceName),
+			},
 		}),
 		jaeger.WithSDK(&sdktrace.Config{
 			DefaultSampler: sdktrace.AlwaysSample(),
 		}),
 	)
-	return flush, err
+	if err != nil {
+		return func() {}, err
+	}
+	return flush, nil
 }
@@ -0,0 +1,99 @@
+package routing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/gorilla/mux"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+//The header the request ID is read from, and echoed back on, by ResultMiddleware
+const REQUEST_ID_HEADER = "X-Request-Id"
+
+//The fields ResultMiddleware sets on each request's result, see common.IResult.With
+const (
+	RESULT_FIELD_METHOD      = "method"
+	RESULT_FIELD_PATH        = "path"
+	RESULT_FIELD_ROUTE       = "route"
+	RESULT_FIELD_REQUEST_ID  = "request_id"
+	RESULT_FIELD_STATUS_CODE = "response_status_code"
+	RESULT_FIELD_LATENCY_MS  = "latency_ms"
+)
+
+/*
+	ResultMiddleware makes one common.IResult per request, so handlers don't have to make (and remember to flush) their
+	own. Handlers get it with common.ResultFromContext(r.Context()).
+	The result is made with common.MakeRequestResult, with the request's method, path, route template and
+	request ID (from the REQUEST_ID_HEADER, or a new one, which is echoed back in the response) as fields. Once the
+	handler returns the response's status code and latency are added, and the result is flushed. If the handler panics
+	the panic is logged with Fatalf and the result is flushed before the panic carries on up the stack.
+	Handlers shouldn't flush the request's result, so calling Flush on it does nothing (and WriteResult can be used).
+	@params
+		configs common.IConfigGetter The config getter to read the LOGGING_ config vars from
+		options ...common.ResultOpt Optional customizations of each result, e.g. common.WithResultSink
+*/
+func ResultMiddleware(configs common.IConfigGetter, options ...common.ResultOpt) MiddleWare {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			result, flush := common.MakeRequestResult(r.Context(), configs, options...)
+
+			request_id := r.Header.Get(REQUEST_ID_HEADER)
+			if request_id == "" {
+				request_id = newRequestID()
+			}
+			w.Header().Set(REQUEST_ID_HEADER, request_id)
+			result.With(RESULT_FIELD_METHOD, r.Method).
+				With(RESULT_FIELD_PATH, r.URL.Path).
+				With(RESULT_FIELD_ROUTE, routeTemplate(r)).
+				With(RESULT_FIELD_REQUEST_ID, request_id)
+
+			wrapper := &HTTPResponseWriterWrapper{ResponseWriter: w}
+			defer func() {
+				status_code := wrapper.status_code
+				recovered := recover()
+				if recovered != nil {
+					status_code = http.StatusInternalServerError
+					result.Fatalf("Panic while handling the request: %v\n%s", recovered, debug.Stack())
+				} else if status_code == 0 {
+					status_code = http.StatusOK //What net/http responds with when the handler writes nothing
+				}
+
+				latency := time.Since(start)
+				result.With(RESULT_FIELD_STATUS_CODE, status_code).
+					With(RESULT_FIELD_LATENCY_MS, float64(latency)/float64(time.Millisecond))
+				result.Infof("Responded with status code %d in %s", status_code, latency)
+				flush()
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+
+			ctx := common.ContextWithResult(r.Context(), result)
+			next.ServeHTTP(wrapper, r.WithContext(ctx))
+		}
+	}
+}
+
+//routeTemplate returns the path template of the mux route that matched r, e.g. "/users/{id}", or "" if there isn't one
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return template
+}
+
+//newRequestID returns a random 16 byte hex request ID
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,126 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"net/http"
+	"strings"
+)
+
+//The Content-Type of the responses written by WriteResultAsProblem, see RFC 7807
+const PROBLEM_JSON_CONTENT_TYPE = "application/problem+json"
+
+//The response body written by WriteResult for a failed result
+type resultErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+/*
+	The response body written by WriteResultAsProblem for a failed result. It is an RFC 7807 problem document, with
+	the same "error" and "message" members as WriteResult as extensions.
+*/
+type resultProblemResponse struct {
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Status  int    `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+/*
+	WriteResult writes result as the response to a request, and then flushes it. A successful result is written as a
+	200 (or its status code, if that is a 2xx) without a body. A failed result is written as a JSON error:
+		{"error": "DEPENDENCY_API_ERROR: not found", "message": "Not Found"}
+	The "error" is the result's response message, or the status name (e.g. "BAD_GATEWAY") if it has none, and the
+	"message" is the status text. See ResultStatusCode for the status code of a failed result.
+	@params
+		w http.ResponseWriter The response to write to
+		result common.IResult The result of handling the request
+*/
+func WriteResult(w http.ResponseWriter, result common.IResult) {
+	writeResult(w, result, false)
+}
+
+/*
+	WriteResultAsProblem is the same as WriteResult, but writes a failed result as an RFC 7807 problem+json document:
+		{"type": "about:blank", "title": "Not Found", "status": 404, "detail": "DEPENDENCY_API_ERROR: not found",
+			"error": "DEPENDENCY_API_ERROR: not found", "message": "Not Found"}
+	@params
+		w http.ResponseWriter The response to write to
+		result common.IResult The result of handling the request
+*/
+func WriteResultAsProblem(w http.ResponseWriter, result common.IResult) {
+	writeResult(w, result, true)
+}
+
+/*
+	ResultStatusCode returns the status code to respond to a failed result with. The result's status code is usually
+	the status a dependency responded with (see APIRequest.Do), so:
+		a 4xx or 5xx is passed on,
+		a 0 (no response) is a 504 if the request timed out (see IResult.Unwrap) and otherwise a 500,
+		and anything else (a 1xx-3xx the request didn't accept) is a 502.
+*/
+func ResultStatusCode(result common.IResult) int {
+	status_code := result.GetStatusCode()
+	switch {
+	case status_code >= http.StatusBadRequest && status_code <= 599:
+		return status_code
+	case status_code == 0 && errors.Is(result, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case status_code == 0:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeResult(w http.ResponseWriter, result common.IResult, as_problem bool) {
+	defer result.Flush()
+
+	if result.WasSuccessful() {
+		status_code := result.GetStatusCode()
+		if status_code < http.StatusOK || status_code >= http.StatusMultipleChoices {
+			status_code = http.StatusOK
+		}
+		w.WriteHeader(status_code)
+		return
+	}
+
+	status_code := ResultStatusCode(result)
+	response := resultErrorResponse{
+		Error:   result.GetResponseMessage(),
+		Message: http.StatusText(status_code),
+	}
+	if response.Error == "" {
+		response.Error = strings.ToUpper(strings.Replace(response.Message, " ", "_", -1))
+	}
+	result.Infof("Responding with status code %d, error: %s", status_code, response.Error)
+
+	var body interface{} = response
+	content_type := "application/json; charset=UTF-8"
+	if as_problem {
+		body = resultProblemResponse{
+			Type:    "about:blank",
+			Title:   response.Message,
+			Status:  status_code,
+			Detail:  result.GetResponseMessage(),
+			Error:   response.Error,
+			Message: response.Message,
+		}
+		content_type = PROBLEM_JSON_CONTENT_TYPE
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		result.Errorf("Error marshalling the error response. Err: %w", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", content_type)
+	w.WriteHeader(status_code)
+	w.Write(data)
+}
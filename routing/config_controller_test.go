@@ -0,0 +1,52 @@
+package routing_test
+
+import (
+	"encoding/json"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/BrandonEchols/common-go-utils/mocks"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigControllerRedactsSecretsAndSensitiveKeys(test *testing.T) {
+	mockCtrl := gomock.NewController(test)
+	defer mockCtrl.Finish()
+
+	configs := mocks.NewMockIConfigGetter(mockCtrl)
+	configs.EXPECT().GetConfigReport().Return([]common.ConfigEntry{
+		{Key: "APP_SECRET", Value: "shh", Source: "env"},
+		{Key: "db.password", Value: "hunter2", Source: "config/config.json"},
+		{Key: "JWT_PRIVATE", Value: "pem", Source: "config/config.json", Secret: true},
+		{Key: "LOGGING_LEVEL", Value: "DEBUG", Source: "config/config.json"},
+		{Key: "DB_PASSWORD", Value: "s3cr${et", Source: "env",
+			Error: `config var DB_PASSWORD from env: invalid value "s3cr${et": unterminated ${ at position 4`},
+	})
+
+	req, _ := http.NewRequest("GET", "http://localhost:8999/admin/config", nil)
+	response := httptest.NewRecorder()
+	routing.GetConfigController(configs).GetConfig(response, req)
+
+	if response.Code != 200 {
+		test.Errorf("HTTP code mismatch. Expected '%v', got '%v'", 200, response.Code)
+	}
+	body := struct {
+		Configs []common.ConfigEntry `json:"configs"`
+	}{}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || len(body.Configs) != 5 {
+		test.Fatalf("Could not parse response body: %s err: %v", response.Body.String(), err)
+	}
+	expected := []string{common.REDACTED_CONFIG_VALUE, common.REDACTED_CONFIG_VALUE, common.REDACTED_CONFIG_VALUE, "DEBUG",
+		common.REDACTED_CONFIG_VALUE}
+	for i, entry := range body.Configs {
+		if entry.Value != expected[i] {
+			test.Errorf("Expected %s to be '%s', got '%s'", entry.Key, expected[i], entry.Value)
+		}
+	}
+	if password := body.Configs[4]; password.Error == "" || strings.Contains(password.Error, "s3cr") {
+		test.Errorf("Expected the password's error to be kept with the value redacted, got '%s'", password.Error)
+	}
+}
@@ -0,0 +1,53 @@
+package routing_test
+
+import (
+	"github.com/BrandonEchols/common-go-utils/mocks"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorsMiddlewareFromConfigAllowsConfiguredOrigins(test *testing.T) {
+	mockCtrl := gomock.NewController(test)
+	defer mockCtrl.Finish()
+
+	configs := mocks.NewMockIConfigGetter(mockCtrl)
+	configs.EXPECT().LookupStringSlice("CORS_ORIGINS").
+		Return([]string{"https://allowed.example.com", "https://other.example.com"}, true, nil).AnyTimes()
+
+	handler := routing.CorsMiddlewareFromConfig(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		configs,
+		"CORS_ORIGINS",
+		[]string{"GET"},
+		[]string{"Content-Type"},
+	)
+
+	testCases := map[string]struct {
+		origin        string
+		expectAllowed bool
+	}{
+		"allowed origin":    {"https://allowed.example.com", true},
+		"second origin":     {"https://other.example.com", true},
+		"disallowed origin": {"https://evil.example.com", false},
+	}
+
+	for name, testCase := range testCases {
+		req, _ := http.NewRequest("GET", "http://localhost:8999/thing", nil)
+		req.Header.Set("Origin", testCase.origin)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, req)
+
+		allowed_origin := response.Header().Get("Access-Control-Allow-Origin")
+		if testCase.expectAllowed && allowed_origin != testCase.origin {
+			test.Errorf("%s: Expected Access-Control-Allow-Origin '%s', got '%s'", name, testCase.origin, allowed_origin)
+		}
+		if !testCase.expectAllowed && allowed_origin != "" {
+			test.Errorf("%s: Expected no Access-Control-Allow-Origin, got '%s'", name, allowed_origin)
+		}
+	}
+}
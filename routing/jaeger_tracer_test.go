@@ -0,0 +1,21 @@
+package routing_test
+
+import (
+	"errors"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"testing"
+)
+
+func TestInitializeJaegerTracerReturnsANoOpFlushWithItsError(test *testing.T) {
+	flush, err := routing.InitializeJaegerTracer(common.GetConfigGetter("testdata/missing.json"))
+
+	config_errs := common.ConfigErrors{}
+	if !errors.As(err, &config_errs) || len(config_errs) != 3 {
+		test.Errorf("Expected the three missing jaeger configs, got %v", err)
+	}
+	if flush == nil {
+		test.Fatal("Expected a flush func along with the error")
+	}
+	flush()
+}
@@ -0,0 +1,91 @@
+package routing_test
+
+import (
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getResultRouter(sink common.IResultSink, handler http.HandlerFunc) *routing.CustomRouter {
+	configs := common.GetConfigGetter("testdata/missing.json")
+	router := routing.GetCustomRouter(mux.NewRouter(), []routing.MiddleWare{
+		routing.ResultMiddleware(configs, common.WithResultSink(sink)),
+	})
+	router.RegisterRoute(routing.Route{Method: "GET", Path: "/things/{id}", HandlerFunc: handler})
+	return router
+}
+
+func TestResultMiddlewareGivesEachRequestAFlushedResult(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	router := getResultRouter(sink, func(w http.ResponseWriter, r *http.Request) {
+		result := common.ResultFromContext(r.Context())
+		result.Infof("Getting thing %s", mux.Vars(r)["id"])
+		result.With("thing_id", mux.Vars(r)["id"]).Flush() //Handlers can't flush the request's result, even through With
+		result.MergeWithResult(result)                     //Merging the result into itself doesn't duplicate its entries
+		result.SetStatusCode(http.StatusNotFound)
+		routing.WriteResult(w, result)
+	})
+
+	req := httptest.NewRequest("GET", "/things/42", nil)
+	req.Header.Set(routing.REQUEST_ID_HEADER, "abc-123")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusNotFound || response.Header().Get(routing.REQUEST_ID_HEADER) != "abc-123" {
+		test.Errorf("Expected a 404 with the request ID echoed back, got %d %v", response.Code, response.Header())
+	}
+	results, ok := sink.WaitForResults(1, time.Second)
+	if !ok {
+		test.Fatal("Expected the request's result to be flushed")
+	}
+	time.Sleep(20 * time.Millisecond) //The handler's and WriteResult's Flush shouldn't write a second result
+	if results = sink.GetResults(); len(results) != 1 {
+		test.Fatalf("Expected one result for the request, got %d", len(results))
+	}
+	fields := results[0].Fields
+	if fields[routing.RESULT_FIELD_METHOD] != "GET" || fields[routing.RESULT_FIELD_PATH] != "/things/42" ||
+		fields[routing.RESULT_FIELD_ROUTE] != "/things/{id}" || fields[routing.RESULT_FIELD_REQUEST_ID] != "abc-123" ||
+		fields[routing.RESULT_FIELD_STATUS_CODE] != http.StatusNotFound || fields["thing_id"] != "42" {
+		test.Errorf("Unexpected result fields %v", fields)
+	}
+	if _, ok := fields[routing.RESULT_FIELD_LATENCY_MS].(float64); !ok {
+		test.Errorf("Expected the latency to be recorded, got %v", fields)
+	}
+	if strings.Count(results[0].Output, "Getting thing 42") != 1 {
+		test.Errorf("Expected the handler's logs once, got '%s'", results[0].Output)
+	}
+}
+
+func TestResultMiddlewareFlushesTheResultWhenTheHandlerPanics(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	router := getResultRouter(sink, func(w http.ResponseWriter, r *http.Request) {
+		common.ResultFromContext(r.Context()).Infof("About to panic")
+		panic("oh no")
+	})
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != "oh no" {
+				test.Errorf("Expected the panic to carry on up the stack, got %v", recovered)
+			}
+		}()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/things/42", nil))
+	}()
+
+	results, ok := sink.WaitForResults(1, time.Second)
+	if !ok {
+		test.Fatal("Expected the request's result to be flushed")
+	}
+	if results[0].Level != common.LEVEL_FATAL || results[0].Fields[routing.RESULT_FIELD_STATUS_CODE] != http.StatusInternalServerError ||
+		results[0].Fields[routing.RESULT_FIELD_REQUEST_ID] == "" {
+		test.Errorf("Expected a fatal result with a 500 and a generated request ID, got %+v", results[0])
+	}
+	if !strings.Contains(results[0].Output, "About to panic") || !strings.Contains(results[0].Output, "Panic while handling the request: oh no") {
+		test.Errorf("Expected the handler's logs and the panic, got '%s'", results[0].Output)
+	}
+}
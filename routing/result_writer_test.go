@@ -0,0 +1,77 @@
+package routing_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/BrandonEchols/common-go-utils/common"
+	"github.com/BrandonEchols/common-go-utils/routing"
+	"github.com/BrandonEchols/common-go-utils/test_helpers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteResultMapsFailedResultsToErrorResponses(test *testing.T) {
+	timed_out := common.MakeDefaultCommonResult()
+	timed_out.Errorf("Error doing request. Err: %w", fmt.Errorf("Get: %w", context.DeadlineExceeded))
+
+	not_found := common.MakeDefaultCommonResult()
+	not_found.SetStatusCode(http.StatusNotFound)
+	not_found.SetResponseMessage("DEPENDENCY_API_ERROR: not found")
+
+	redirected := common.MakeDefaultCommonResult()
+	redirected.SetStatusCode(http.StatusFound)
+
+	for name, expected := range map[string]struct {
+		result      common.IResult
+		status_code int
+		err         string
+	}{
+		"no response":    {common.MakeDefaultCommonResult(), http.StatusInternalServerError, "INTERNAL_SERVER_ERROR"},
+		"timed out":      {timed_out, http.StatusGatewayTimeout, "GATEWAY_TIMEOUT"},
+		"dependency 404": {not_found, http.StatusNotFound, "DEPENDENCY_API_ERROR: not found"},
+		"unexpected 302": {redirected, http.StatusBadGateway, "BAD_GATEWAY"},
+	} {
+		response := httptest.NewRecorder()
+		routing.WriteResult(response, expected.result)
+		if content_type := response.Header().Get("Content-Type"); content_type != "application/json; charset=UTF-8" {
+			test.Errorf("%s: unexpected Content-Type %s", name, content_type)
+		}
+		test_helpers.AssertHttpStatusAndErrorAndMessage(test, response, expected.status_code, expected.err, http.StatusText(expected.status_code))
+	}
+}
+
+func TestWriteResultAsProblemWritesAProblemDocument(test *testing.T) {
+	sink := common.GetMemoryResultSink()
+	result := common.MakeDefaultCommonResult(common.WithResultSink(sink))
+	result.SetStatusCode(http.StatusServiceUnavailable)
+	result.SetResponseMessage("DEPENDENCY_API_ERROR: down for maintenance")
+
+	response := httptest.NewRecorder()
+	routing.WriteResultAsProblem(response, result)
+	if content_type := response.Header().Get("Content-Type"); content_type != routing.PROBLEM_JSON_CONTENT_TYPE {
+		test.Errorf("Expected the problem+json Content-Type, got %s", content_type)
+	}
+	problem := map[string]interface{}{}
+	json.Unmarshal(response.Body.Bytes(), &problem)
+	if problem["type"] != "about:blank" || problem["title"] != "Service Unavailable" || problem["status"] != float64(503) ||
+		problem["detail"] != "DEPENDENCY_API_ERROR: down for maintenance" {
+		test.Errorf("Unexpected problem document %v", problem)
+	}
+	test_helpers.AssertHttpStatusAndErrorAndMessage(test, response, 503, "DEPENDENCY_API_ERROR: down for maintenance", "Service Unavailable")
+
+	if _, ok := sink.WaitForResults(1, time.Second); !ok {
+		test.Error("Expected the result to be flushed")
+	}
+}
+
+func TestWriteResultWritesSuccessfulResultsWithoutABody(test *testing.T) {
+	response := httptest.NewRecorder()
+	routing.WriteResult(response, test_helpers.GoodResult())
+	test_helpers.AssertHttpStatusAndErrorAndMessage(test, response, http.StatusOK, "", "")
+	if response.Body.Len() != 0 {
+		test.Errorf("Expected no body, got %s", response.Body.String())
+	}
+}